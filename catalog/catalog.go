@@ -0,0 +1,216 @@
+package catalog
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+const (
+	// HoursPerMonth is the (average) number of hours in a month used to estimate monthly costs
+	HoursPerMonth float64 = 730
+	// pageSize is the number of instance types requested per page
+	pageSize int = 100
+)
+
+// fallback is a snapshot of the Koyeb instance type catalog used when the API is unavailable
+// It has the same structure as the API's response and its prices may be out of date
+//
+//go:embed instances.json
+var fallback []byte
+
+// InstanceType is a Koyeb instance type and its price
+type InstanceType struct {
+	ID             string
+	DisplayName    string
+	Type           string
+	VCPU           float64
+	Memory         string
+	Disk           string
+	PricePerSecond float64
+	PriceHourly    float64
+	PriceMonthly   float64
+	Regions        []string
+}
+
+// MemoryMB is a method that returns the instance type's memory in megabytes
+// Memory is represented by the catalog as e.g. "512MB" or "2GB"
+// Returns zero if the memory cannot be parsed
+func (t InstanceType) MemoryMB() float64 {
+	s := strings.ToUpper(strings.TrimSpace(t.Memory))
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "GB"):
+		s, multiplier = strings.TrimSuffix(s, "GB"), 1024
+	case strings.HasSuffix(s, "MB"):
+		s = strings.TrimSuffix(s, "MB")
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return f * multiplier
+}
+
+// Catalog is the Koyeb instance type catalog
+// It is initialized from the bundled fallback and refreshed from the Koyeb API
+type Catalog struct {
+	client *koyeb.APIClient
+	logger *slog.Logger
+
+	mu    sync.RWMutex
+	types map[string]InstanceType
+}
+
+// New is a function that creates a new Catalog initialized from the bundled fallback
+func New(client *koyeb.APIClient, l *slog.Logger) (*Catalog, error) {
+	logger := l.With("catalog", "instances")
+
+	var resp koyeb.ListCatalogInstancesReply
+	if err := json.Unmarshal(fallback, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Catalog{
+		client: client,
+		logger: logger,
+		types:  index(resp.Instances),
+	}, nil
+}
+
+// Refresh is a method that replaces the Catalog's instance types with those listed by the Koyeb API
+// The Catalog is unchanged if the API is unavailable
+func (c *Catalog) Refresh(ctx context.Context) error {
+	items := []koyeb.CatalogInstanceListItem{}
+	for offset := 0; ; offset += pageSize {
+		rqst := c.client.CatalogInstancesApi.ListCatalogInstances(ctx).
+			Limit(strconv.Itoa(pageSize)).
+			Offset(strconv.Itoa(offset))
+		resp, _, err := rqst.Execute()
+		if err != nil {
+			return err
+		}
+
+		items = append(items, resp.Instances...)
+		if len(resp.Instances) < pageSize || int64(len(items)) >= resp.GetCount() {
+			break
+		}
+	}
+
+	if len(items) == 0 {
+		return errors.New("catalog has no instance types")
+	}
+
+	types := index(items)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.types = types
+
+	return nil
+}
+
+// Run is a method that refreshes the Catalog immediately and then every interval until ctx is done
+// If interval is not positive, the Catalog is refreshed once
+func (c *Catalog) Run(ctx context.Context, interval time.Duration) {
+	logger := c.logger.With("method", "run")
+
+	if interval <= 0 {
+		if err := c.Refresh(ctx); err != nil {
+			logger.Info("unable to refresh catalog; using previous catalog", "err", err)
+		}
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil {
+			logger.Info("unable to refresh catalog; using previous catalog", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lookup is a method that returns the instance type by its ID or alias
+func (c *Catalog) Lookup(id string) (InstanceType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, ok := c.types[id]
+	return t, ok
+}
+
+// Types is a method that returns every instance type
+func (c *Catalog) Types() []InstanceType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	types := make([]InstanceType, 0, len(c.types))
+	seen := map[string]bool{}
+	for _, t := range c.types {
+		if seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		types = append(types, t)
+	}
+	return types
+}
+
+// index is a function that converts catalog items into instance types keyed by their IDs and aliases
+func index(items []koyeb.CatalogInstanceListItem) map[string]InstanceType {
+	types := map[string]InstanceType{}
+	for _, item := range items {
+		t := InstanceType{
+			ID:             item.GetId(),
+			DisplayName:    item.GetDisplayName(),
+			Type:           item.GetType(),
+			VCPU:           float64(item.GetVcpuShares()),
+			Memory:         item.GetMemory(),
+			Disk:           item.GetDisk(),
+			PricePerSecond: parsePrice(item.GetPricePerSecond()),
+			PriceHourly:    parsePrice(item.GetPriceHourly()),
+			PriceMonthly:   parsePrice(item.GetPriceMonthly()),
+			Regions:        item.Regions,
+		}
+
+		// Derive the hourly price if only the per-second price is provided
+		if t.PriceHourly == 0 {
+			t.PriceHourly = t.PricePerSecond * 3600
+		}
+
+		types[t.ID] = t
+		for _, alias := range item.Aliases {
+			types[alias] = t
+		}
+	}
+	return types
+}
+
+// parsePrice is a function that parses a price represented as a string
+// Unparseable prices are treated as zero
+func parsePrice(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
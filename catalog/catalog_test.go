@@ -0,0 +1,237 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+// newTestCatalog is a function that returns a Catalog whose Koyeb API requests are served by handler
+func newTestCatalog(t *testing.T, handler http.Handler) *Catalog {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := koyeb.NewConfiguration()
+	cfg.Servers = koyeb.ServerConfigurations{
+		{
+			URL: server.URL,
+		},
+	}
+	cfg.HTTPClient = server.Client()
+
+	c, err := New(koyeb.NewAPIClient(cfg), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// newTestItem is a function that returns a catalog item with an hourly price
+func newTestItem(id string, priceHourly string) koyeb.CatalogInstanceListItem {
+	item := koyeb.CatalogInstanceListItem{}
+	item.SetId(id)
+	item.SetPriceHourly(priceHourly)
+	return item
+}
+
+func TestNewFallback(t *testing.T) {
+	c := newTestCatalog(t, http.NotFoundHandler())
+
+	var resp koyeb.ListCatalogInstancesReply
+	if err := json.Unmarshal(fallback, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(c.Types()), len(resp.Instances); got != want {
+		t.Errorf("got %d instance types, want %d", got, want)
+	}
+
+	got, ok := c.Lookup("eco-nano")
+	if !ok {
+		t.Fatal("eco-nano not found")
+	}
+	if got.PriceHourly != 0.002236 {
+		t.Errorf("got hourly price %v, want 0.002236", got.PriceHourly)
+	}
+	if got.MemoryMB() != 256 {
+		t.Errorf("got memory %vMB, want 256MB", got.MemoryMB())
+	}
+
+	if _, ok := c.Lookup("unknown"); ok {
+		t.Error("got unknown instance type, want not found")
+	}
+}
+
+func TestMemoryMB(t *testing.T) {
+	tests := []struct {
+		memory string
+		want   float64
+	}{
+		{memory: "512MB", want: 512},
+		{memory: "2GB", want: 2048},
+		{memory: " 1gb ", want: 1024},
+		{memory: "256", want: 256},
+		{memory: "", want: 0},
+		{memory: "lots", want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.memory, func(t *testing.T) {
+			if got := (InstanceType{Memory: test.memory}).MemoryMB(); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	aliased := newTestItem("nano", "0.01")
+	aliased.SetAliases([]string{"gpu-nano"})
+
+	perSecond := koyeb.CatalogInstanceListItem{}
+	perSecond.SetId("small")
+	perSecond.SetPricePerSecond("0.5")
+
+	unparseable := newTestItem("medium", "free")
+
+	types := index([]koyeb.CatalogInstanceListItem{aliased, perSecond, unparseable})
+
+	if got, ok := types["gpu-nano"]; !ok || got.ID != "nano" {
+		t.Errorf("got %+v for alias, want nano", got)
+	}
+	// The hourly price is derived from the per-second price
+	if got := types["small"].PriceHourly; got != 1800 {
+		t.Errorf("got hourly price %v, want 1800", got)
+	}
+	if got := types["medium"].PriceHourly; got != 0 {
+		t.Errorf("got hourly price %v, want 0", got)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	// More instance types than fit on a page
+	count := pageSize + 1
+	c := newTestCatalog(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/catalog/instances" {
+			http.NotFound(w, r)
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		items := []koyeb.CatalogInstanceListItem{}
+		for i := offset; i < min(offset+pageSize, count); i++ {
+			items = append(items, newTestItem(fmt.Sprintf("type-%d", i), "1"))
+		}
+
+		reply := koyeb.ListCatalogInstancesReply{}
+		reply.SetInstances(items)
+		reply.SetCount(int64(count))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reply); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(c.Types()); got != count {
+		t.Errorf("got %d instance types, want %d", got, count)
+	}
+	if _, ok := c.Lookup(fmt.Sprintf("type-%d", count-1)); !ok {
+		t.Error("instance type from the last page not found")
+	}
+	// The fallback is replaced
+	if _, ok := c.Lookup("eco-nano"); ok {
+		t.Error("got fallback instance type, want not found")
+	}
+}
+
+func TestRefreshUnavailable(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			},
+		},
+		{
+			name: "empty",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(koyeb.ListCatalogInstancesReply{}); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestCatalog(t, test.handler)
+			want := len(c.Types())
+
+			if err := c.Refresh(ctx); err == nil {
+				t.Error("got nil error, want error")
+			}
+
+			// The Catalog is unchanged
+			if got := len(c.Types()); got != want {
+				t.Errorf("got %d instance types, want %d", got, want)
+			}
+			if _, ok := c.Lookup("eco-nano"); !ok {
+				t.Error("fallback instance type not found")
+			}
+		})
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	requests := atomic.Int32{}
+	c := newTestCatalog(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		reply := koyeb.ListCatalogInstancesReply{}
+		reply.SetInstances([]koyeb.CatalogInstanceListItem{newTestItem("nano", "1")})
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(reply); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	// Without an interval, Run refreshes the Catalog once and returns
+	done := make(chan struct{})
+	go func() {
+		c.Run(context.Background(), 0)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+	if _, ok := c.Lookup("nano"); !ok {
+		t.Error("refreshed instance type not found")
+	}
+}
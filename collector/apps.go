@@ -33,17 +33,7 @@ func NewAppsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- pr
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the App is up, 0 otherwise",
-			[]string{
-				"id",
-				"name",
-				"organization",
-				"status",
-			},
-			nil,
-		),
+		Up: appUp.Desc(),
 	}
 }
 
@@ -75,9 +65,8 @@ func (c *AppsCollector) Collect(ch chan<- prometheus.Metric) {
 	c.ch <- status
 
 	for _, app := range resp.Apps {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				app.GetId(),
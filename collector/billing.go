@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that BillingCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*BillingCollector)(nil)
+
+// BillingCollector collects Koyeb Organization plan, subscription and invoice metrics
+type BillingCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	PlanInfo           *prometheus.Desc
+	SubscriptionInfo   *prometheus.Desc
+	NextInvoiceAmount  *prometheus.Desc
+	AmountPayable      *prometheus.Desc
+	AmountPaid         *prometheus.Desc
+	AmountRemaining    *prometheus.Desc
+	UnpaidInvoices     *prometheus.Desc
+	PaymentFailed      *prometheus.Desc
+	Trialing           *prometheus.Desc
+	TrialEnd           *prometheus.Desc
+	TrialMaxSpend      *prometheus.Desc
+	CurrentSpend       *prometheus.Desc
+	TrialCreditBalance *prometheus.Desc
+}
+
+// NewBillingCollector is a function that creates a new BillingCollector
+func NewBillingCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *BillingCollector {
+	subsystem := "billing"
+	logger := l.With("collector", subsystem)
+
+	return &BillingCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		PlanInfo:           organizationPlanInfo.Desc(),
+		SubscriptionInfo:   billingSubscriptionInfo.Desc(),
+		NextInvoiceAmount:  billingNextInvoiceAmount.Desc(),
+		AmountPayable:      billingAmountPayable.Desc(),
+		AmountPaid:         billingAmountPaid.Desc(),
+		AmountRemaining:    billingAmountRemaining.Desc(),
+		UnpaidInvoices:     billingUnpaidInvoices.Desc(),
+		PaymentFailed:      billingPaymentFailed.Desc(),
+		Trialing:           billingTrialing.Desc(),
+		TrialEnd:           billingTrialEndTimestampSeconds.Desc(),
+		TrialMaxSpend:      billingTrialMaxSpend.Desc(),
+		CurrentSpend:       billingCurrentSpend.Desc(),
+		TrialCreditBalance: billingTrialCreditBalance.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *BillingCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	rqst := c.client.ProfileApi.GetCurrentOrganization(c.ctx)
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		msg := "unable to get Organization"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	organization := resp.GetOrganization()
+	organizationID := organization.GetId()
+
+	ch <- constMetric(
+		c.PlanInfo,
+		1.0,
+		[]string{
+			organizationID,
+			string(organization.GetPlan()),
+		}...,
+	)
+
+	trialing := 0.0
+	if organization.GetTrialing() {
+		trialing = 1.0
+	}
+	ch <- constMetric(
+		c.Trialing,
+		trialing,
+		[]string{
+			organizationID,
+		}...,
+	)
+	if organization.TrialEndsAt != nil {
+		ch <- constMetric(
+			c.TrialEnd,
+			float64(organization.GetTrialEndsAt().Unix()),
+			[]string{
+				organizationID,
+			}...,
+		)
+	}
+
+	// The currency of the Organization's subscription
+	currency := ""
+	if subscriptionID := organization.GetCurrentSubscriptionId(); subscriptionID != "" {
+		currency = c.collectSubscription(ch, organizationID, subscriptionID)
+	}
+
+	c.collectInvoices(ch, organizationID, currency)
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *BillingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.PlanInfo
+	ch <- c.SubscriptionInfo
+	ch <- c.NextInvoiceAmount
+	ch <- c.AmountPayable
+	ch <- c.AmountPaid
+	ch <- c.AmountRemaining
+	ch <- c.UnpaidInvoices
+	ch <- c.PaymentFailed
+	ch <- c.Trialing
+	ch <- c.TrialEnd
+	ch <- c.TrialMaxSpend
+	ch <- c.CurrentSpend
+	ch <- c.TrialCreditBalance
+}
+
+// collectSubscription is a method that collects metrics for the Organization's subscription
+// Returns the subscription's currency or "" if the subscription can't be retrieved
+func (c *BillingCollector) collectSubscription(ch chan<- prometheus.Metric, organizationID, subscriptionID string) string {
+	logger := c.logger.With("method", "collectSubscription")
+
+	resp, _, err := c.client.SubscriptionsApi.GetSubscription(c.ctx, subscriptionID).Execute()
+	if err != nil {
+		logger.Error("unable to get Subscription", "err", err)
+		return ""
+	}
+
+	subscription := resp.GetSubscription()
+
+	ch <- constMetric(
+		c.SubscriptionInfo,
+		1.0,
+		[]string{
+			organizationID,
+			subscription.GetId(),
+			string(subscription.GetStatus()),
+		}...,
+	)
+
+	labels := []string{
+		organizationID,
+		subscription.GetCurrency(),
+	}
+
+	for desc, amount := range map[*prometheus.Desc]string{
+		c.AmountPayable:   subscription.GetAmountPayable(),
+		c.AmountPaid:      subscription.GetAmountPaid(),
+		c.AmountRemaining: subscription.GetAmountRemaining(),
+		c.CurrentSpend:    subscription.GetCurrentSpend(),
+	} {
+		ch <- constMetric(
+			desc,
+			cents(amount),
+			labels...,
+		)
+	}
+
+	if subscription.GetTrialing() {
+		maxSpend := cents(subscription.GetTrialMaxSpend())
+		ch <- constMetric(
+			c.TrialMaxSpend,
+			maxSpend,
+			labels...,
+		)
+		ch <- constMetric(
+			c.TrialCreditBalance,
+			maxSpend-cents(subscription.GetCurrentSpend()),
+			labels...,
+		)
+	}
+
+	failed := 0.0
+	errorCode := ""
+	if failure, ok := subscription.GetPaymentFailureOk(); ok {
+		failed = 1.0
+		errorCode = failure.GetErrorCode()
+	}
+	ch <- constMetric(
+		c.PaymentFailed,
+		failed,
+		[]string{
+			organizationID,
+			errorCode,
+		}...,
+	)
+
+	return subscription.GetCurrency()
+}
+
+// collectInvoices is a method that collects metrics for the Organization's invoices
+// The next invoice's amount is labeled by the subscription's currency or, if there's none, the invoice's own currency
+func (c *BillingCollector) collectInvoices(ch chan<- prometheus.Metric, organizationID, currency string) {
+	logger := c.logger.With("method", "collectInvoices")
+
+	if resp, _, err := c.client.BillingApi.HasUnpaidInvoices(c.ctx).Execute(); err != nil {
+		logger.Error("unable to determine whether there are unpaid invoices", "err", err)
+	} else {
+		unpaid := 0.0
+		if resp.GetHasUnpaidInvoices() {
+			unpaid = 1.0
+		}
+		ch <- constMetric(
+			c.UnpaidInvoices,
+			unpaid,
+			[]string{
+				organizationID,
+			}...,
+		)
+	}
+
+	if resp, _, err := c.client.BillingApi.NextInvoice(c.ctx).Execute(); err != nil {
+		logger.Error("unable to get next invoice", "err", err)
+	} else {
+		amount := 0
+		for _, line := range resp.Lines {
+			amount += int(line.GetAmountExcludingTax())
+		}
+		if currency == "" {
+			currency, _ = resp.StripeInvoice["currency"].(string)
+		}
+		ch <- constMetric(
+			c.NextInvoiceAmount,
+			float64(amount)/100,
+			[]string{
+				organizationID,
+				currency,
+			}...,
+		)
+	}
+}
+
+// cents is a function that converts an amount in cents represented as a string into whole currency units
+// Unparseable amounts are treated as zero
+func cents(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f / 100
+}
@@ -0,0 +1,326 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/catalog"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that BudgetCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*BudgetCollector)(nil)
+
+// spend is the amount spent by an Organization or an App during the current month
+type spend struct {
+	id   string
+	name string
+	// spent is the amount spent to date
+	// projected is the amount that will have been spent at the end of the month
+	spent     float64
+	projected float64
+}
+
+// BudgetCollector collects monthly budget and (projected) spend metrics for the Organization and its Apps
+type BudgetCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// catalog provides the prices of instance types
+	catalog *catalog.Catalog
+
+	// Monthly budgets for the Organization and for Apps keyed by App ID or name
+	// A zero Organization budget is not monitored
+	organization float64
+	apps         map[string]float64
+
+	// warned records the App budget keys that have been reported as matching no App
+	warned map[string]bool
+
+	Limit     *prometheus.Desc
+	Spent     *prometheus.Desc
+	Projected *prometheus.Desc
+}
+
+// NewBudgetCollector is a function that creates a new BudgetCollector
+// Spend is derived from the Organization's usage priced using the catalog
+// If usage is unavailable, spend is derived from the currently running Instances as if they had run all month
+func NewBudgetCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, catalog *catalog.Catalog, organization float64, apps map[string]float64) *BudgetCollector {
+	subsystem := "budget"
+	logger := l.With("collector", subsystem)
+
+	return &BudgetCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		catalog: catalog,
+
+		organization: organization,
+		apps:         apps,
+
+		warned: map[string]bool{},
+
+		Limit:     budgetLimit.Desc(),
+		Spent:     budgetSpent.Desc(),
+		Projected: budgetProjected.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *BudgetCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	if c.organization <= 0 && len(c.apps) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	// App name keyed by App ID
+	// Apps are only listed when App budgets are configured
+	var names map[string]string
+	if len(c.apps) > 0 {
+		if items, err := listApps(c.ctx, c.client); err != nil {
+			logger.Info("unable to list Apps", "err", err)
+		} else {
+			names = map[string]string{}
+			for _, app := range items {
+				names[app.GetId()] = app.GetName()
+			}
+		}
+	}
+
+	organization, apps, err := c.usage(start, now, end)
+	if err != nil {
+		logger.Info("unable to get Organization usage; using running Instances", "err", err)
+
+		organization, apps, err = c.running(names, start, now, end)
+		if err != nil {
+			msg := "unable to list Instances"
+			logger.Error(msg, "err", err)
+
+			// Send probe unhealthy status
+			// Doesn't surface the API error message (should it!?)
+			status := probe.Status{
+				Healthy: false,
+				Message: msg,
+			}
+			c.ch <- status
+
+			return
+		}
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	if c.organization > 0 {
+		c.collect(ch, "organization", c.organization, organization)
+	}
+
+	// Spend keyed by App ID
+	// Apps without usage have spent nothing
+	spends := map[string]spend{}
+	for id, name := range names {
+		spends[id] = spend{
+			id:   id,
+			name: name,
+		}
+	}
+	for _, app := range apps {
+		if app.name == "" {
+			app.name = names[app.id]
+		}
+		spends[app.id] = app
+	}
+
+	// App budget keys that match an App
+	matched := map[string]bool{}
+	for _, app := range spends {
+		key := app.id
+		limit, ok := c.apps[key]
+		if !ok && app.name != "" {
+			key = app.name
+			limit, ok = c.apps[key]
+		}
+		if !ok {
+			continue
+		}
+		matched[key] = true
+		c.collect(ch, "app", limit, app)
+	}
+
+	// Without the list of Apps, a key may match an App that has no spend
+	if names == nil {
+		return
+	}
+	for key := range c.apps {
+		if matched[key] || c.warned[key] {
+			continue
+		}
+		logger.Warn("App budget matches no App", "app", key)
+		c.warned[key] = true
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *BudgetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Limit
+	ch <- c.Spent
+	ch <- c.Projected
+}
+
+// collect is a method that collects the budget metrics for an Organization or an App
+func (c *BudgetCollector) collect(ch chan<- prometheus.Metric, scope string, limit float64, s spend) {
+	labels := []string{
+		scope,
+		s.id,
+		s.name,
+	}
+
+	ch <- constMetric(
+		c.Limit,
+		limit,
+		labels...,
+	)
+	ch <- constMetric(
+		c.Spent,
+		s.spent,
+		labels...,
+	)
+	ch <- constMetric(
+		c.Projected,
+		s.projected,
+		labels...,
+	)
+}
+
+// usage is a method that prices the Organization's usage of instance types since start of the month
+// Spend is projected linearly to the end of the month
+func (c *BudgetCollector) usage(start, now, end time.Time) (spend, []spend, error) {
+	logger := c.logger.With("method", "usage")
+
+	rqst := c.client.UsagesApi.GetOrganizationUsage(c.ctx).
+		StartingTime(start).
+		EndingTime(now)
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return spend{}, nil, err
+	}
+
+	usage := resp.GetUsage()
+	organization := spend{
+		id: usage.GetOrganizationId(),
+	}
+
+	// Spend keyed by App ID
+	apps := map[string]*spend{}
+	for _, period := range usage.GetPeriods() {
+		for _, app := range period.Apps {
+			a, ok := apps[app.GetAppId()]
+			if !ok {
+				a = &spend{
+					id:   app.GetAppId(),
+					name: app.GetAppName(),
+				}
+				apps[app.GetAppId()] = a
+			}
+
+			for _, service := range app.Services {
+				for _, region := range service.GetRegions() {
+					for instanceType, instance := range region.GetInstances() {
+						t, ok := c.catalog.Lookup(instanceType)
+						if !ok {
+							logger.Info("unable to find instance type in catalog", "type", instanceType)
+							continue
+						}
+
+						a.spent += float64(instance.GetDurationSeconds()) * t.PricePerSecond
+					}
+				}
+			}
+		}
+	}
+
+	// Fraction of the month that has elapsed
+	elapsed := now.Sub(start).Seconds() / end.Sub(start).Seconds()
+
+	result := make([]spend, 0, len(apps))
+	for _, a := range apps {
+		if elapsed > 0 {
+			a.projected = a.spent / elapsed
+		}
+		organization.spent += a.spent
+		organization.projected += a.projected
+		result = append(result, *a)
+	}
+
+	return organization, result, nil
+}
+
+// running is a method that prices the currently running Instances as if they had run since the start of the month
+// Spend is projected assuming the Instances run until the end of the month
+// Apps are named using names (keyed by App ID)
+func (c *BudgetCollector) running(names map[string]string, start, now, end time.Time) (spend, []spend, error) {
+	logger := c.logger.With("method", "running")
+
+	instances, err := listInstances(c.ctx, c.client)
+	if err != nil {
+		return spend{}, nil, err
+	}
+
+	elapsed := now.Sub(start).Hours()
+	month := end.Sub(start).Hours()
+
+	organization := spend{}
+
+	// Spend keyed by App ID
+	apps := map[string]*spend{}
+	for _, instance := range instances {
+		if !running(instance.GetStatus()) {
+			continue
+		}
+
+		t, ok := c.catalog.Lookup(instance.GetType())
+		if !ok {
+			logger.Info("unable to find instance type in catalog", "type", instance.GetType())
+			continue
+		}
+
+		organization.id = instance.GetOrganizationId()
+
+		a, ok := apps[instance.GetAppId()]
+		if !ok {
+			a = &spend{
+				id:   instance.GetAppId(),
+				name: names[instance.GetAppId()],
+			}
+			apps[instance.GetAppId()] = a
+		}
+
+		a.spent += t.PriceHourly * elapsed
+		a.projected += t.PriceHourly * month
+	}
+
+	result := make([]spend, 0, len(apps))
+	for _, a := range apps {
+		organization.spent += a.spent
+		organization.projected += a.projected
+		result = append(result, *a)
+	}
+
+	return organization, result, nil
+}
@@ -0,0 +1,290 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/catalog"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// testUsage is the Organization's usage of instance types by two Apps over two periods
+// The usage of an instance type that is not in the catalog is ignored
+const testUsage = `{
+  "usage": {
+    "organization_id": "o1",
+    "periods": {
+      "2024-04": {
+        "apps": [
+          {
+            "app_id": "a1",
+            "app_name": "web",
+            "services": [
+              {"service_id": "s1", "regions": {"fra": {"instances": {"eco-nano": {"duration_seconds": 3600}}}}}
+            ]
+          },
+          {
+            "app_id": "a2",
+            "app_name": "worker",
+            "services": [
+              {"service_id": "s2", "regions": {"fra": {"instances": {"eco-nano": {"duration_seconds": 7200}}}}},
+              {"service_id": "s3", "regions": {"was": {"instances": {"unknown": {"duration_seconds": 7200}}}}}
+            ]
+          }
+        ]
+      },
+      "2024-04-02": {
+        "apps": [
+          {
+            "app_id": "a1",
+            "app_name": "web",
+            "services": [
+              {"service_id": "s1", "regions": {"was": {"instances": {"eco-nano": {"duration_seconds": 3600}}}}}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}`
+
+// newTestInstanceOf is a function that returns an Instance of the instance type of the App in the status
+func newTestInstanceOf(id, appID, instanceType string, status koyeb.InstanceStatus) koyeb.InstanceListItem {
+	instance := koyeb.InstanceListItem{}
+	instance.SetId(id)
+	instance.SetOrganizationId("o1")
+	instance.SetAppId(appID)
+	instance.SetType(instanceType)
+	instance.SetStatus(status)
+	return instance
+}
+
+// newTestBudget is a function that returns a BudgetCollector whose usage is testUsage (or unavailable)
+// The running Instances are two of App a1 (one stopped) and two of App a2 (one of an unknown instance type)
+// The Apps are a1 (web) and a3 (idle), which has neither usage nor running Instances
+func newTestBudget(t *testing.T, usage bool, organization float64, apps map[string]float64) *BudgetCollector {
+	t.Helper()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/usages":
+			if !usage {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(testUsage))
+		case "/v1/instances":
+			reply := koyeb.ListInstancesReply{}
+			reply.SetInstances([]koyeb.InstanceListItem{
+				newTestInstanceOf("i1", "a1", "eco-nano", koyeb.INSTANCESTATUS_HEALTHY),
+				newTestInstanceOf("i2", "a1", "eco-nano", koyeb.INSTANCESTATUS_STOPPED),
+				newTestInstanceOf("i3", "a2", "eco-nano", koyeb.INSTANCESTATUS_STARTING),
+				newTestInstanceOf("i4", "a2", "unknown", koyeb.INSTANCESTATUS_HEALTHY),
+			})
+			writeJSON(t, w, reply)
+		case "/v1/apps":
+			items := []koyeb.AppListItem{}
+			for id, name := range map[string]string{
+				"a1": "web",
+				"a3": "idle",
+			} {
+				app := koyeb.AppListItem{}
+				app.SetId(id)
+				app.SetName(name)
+				items = append(items, app)
+			}
+
+			reply := koyeb.ListAppsReply{}
+			reply.SetApps(items)
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	logger := newTestLogger()
+	instanceTypes, err := catalog.New(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewBudgetCollector(context.Background(), client, newTestStatus(t), logger, instanceTypes, organization, apps)
+}
+
+// approximately is a function that returns whether two amounts are equal to within rounding
+func approximately(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBudgetCollectorProjection(t *testing.T) {
+	instanceTypes, err := catalog.New(nil, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nano, ok := instanceTypes.Lookup("eco-nano")
+	if !ok {
+		t.Fatal("eco-nano not found")
+	}
+
+	// A fifth of the way through a 30-day month
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(6 * 24 * time.Hour)
+	end := start.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name  string
+		usage bool
+		want  map[string]spend
+	}{
+		{
+			// Usage is priced per second and projected linearly to the end of the month
+			name:  "usage",
+			usage: true,
+			want: map[string]spend{
+				"a1": {
+					name:      "web",
+					spent:     7200 * nano.PricePerSecond,
+					projected: 7200 * nano.PricePerSecond * 5,
+				},
+				"a2": {
+					name:      "worker",
+					spent:     7200 * nano.PricePerSecond,
+					projected: 7200 * nano.PricePerSecond * 5,
+				},
+			},
+		},
+		{
+			// Running Instances are priced as if they ran from the start to the end of the month
+			name:  "running",
+			usage: false,
+			want: map[string]spend{
+				"a1": {
+					name:      "web",
+					spent:     nano.PriceHourly * 6 * 24,
+					projected: nano.PriceHourly * 30 * 24,
+				},
+				"a2": {
+					name:      "",
+					spent:     nano.PriceHourly * 6 * 24,
+					projected: nano.PriceHourly * 30 * 24,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestBudget(t, test.usage, 0, nil)
+
+			project := func(start, now, end time.Time) (spend, []spend, error) {
+				return c.running(map[string]string{"a1": "web"}, start, now, end)
+			}
+			if test.usage {
+				project = c.usage
+			}
+			organization, apps, err := project(start, now, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(apps) != len(test.want) {
+				t.Errorf("got %d Apps, want %d", len(apps), len(test.want))
+			}
+
+			total := spend{}
+			for _, app := range apps {
+				want, ok := test.want[app.id]
+				if !ok {
+					t.Errorf("unexpected App %q", app.id)
+					continue
+				}
+				if app.name != want.name {
+					t.Errorf("App %q: got name %q, want %q", app.id, app.name, want.name)
+				}
+				if !approximately(app.spent, want.spent) {
+					t.Errorf("App %q: got spent %v, want %v", app.id, app.spent, want.spent)
+				}
+				if !approximately(app.projected, want.projected) {
+					t.Errorf("App %q: got projected %v, want %v", app.id, app.projected, want.projected)
+				}
+				total.spent += want.spent
+				total.projected += want.projected
+			}
+
+			// The Organization's spend is the sum of its Apps' spend
+			if organization.id != "o1" {
+				t.Errorf("got Organization %q, want %q", organization.id, "o1")
+			}
+			if !approximately(organization.spent, total.spent) {
+				t.Errorf("got Organization spent %v, want %v", organization.spent, total.spent)
+			}
+			if !approximately(organization.projected, total.projected) {
+				t.Errorf("got Organization projected %v, want %v", organization.projected, total.projected)
+			}
+		})
+	}
+}
+
+func TestBudgetCollectorLimits(t *testing.T) {
+	// App budgets are keyed by ID or name and Apps without a budget are not reported
+	// An App without usage has spent nothing and a budget that matches no App is not reported
+	c := newTestBudget(t, true, 100, map[string]float64{
+		"web":     10,
+		"a3":      30,
+		"missing": 50,
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Budget limits and spend keyed by scope and ID
+	got := map[string]float64{}
+	spent := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			key := labels["scope"] + "/" + labels["id"]
+			switch family.GetName() {
+			case budgetLimit.FQName():
+				got[key] = metric.GetGauge().GetValue()
+			case budgetSpent.FQName():
+				spent[key] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	want := map[string]float64{
+		"organization/o1": 100,
+		"app/a1":          10,
+		"app/a3":          30,
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d budgets, want %d", len(got), len(want))
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s: got budget %v, want %v", key, got[key], value)
+		}
+	}
+
+	if value, ok := spent["app/a3"]; !ok || value != 0 {
+		t.Errorf("app/a3: got spent %v (%t), want 0", value, ok)
+	}
+
+	// The budget that matches no App is reported once
+	if !c.warned["missing"] || len(c.warned) != 1 {
+		t.Errorf("got warned %v, want [missing]", c.warned)
+	}
+}
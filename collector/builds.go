@@ -0,0 +1,288 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// buildLogLines is the number of (most recent) build log lines fetched to classify a failed build
+	buildLogLines int = 500
+	// buildClassifications bounds the number of failed builds classified (and build logs fetched) per scrape
+	buildClassifications int = 10
+	// UnknownBuildFailure is the reason of failed builds that match no rule
+	UnknownBuildFailure string = "unknown"
+)
+
+// Ensure that BuildsCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*BuildsCollector)(nil)
+
+// BuildRule is a regular expression that classifies a failed build's log as failing for a reason
+type BuildRule struct {
+	Reason  string
+	Pattern *regexp.Regexp
+}
+
+// ParseBuildRule is a function that parses a build rule represented as {reason}={regex}
+func ParseBuildRule(s string) (BuildRule, error) {
+	reason, expr, ok := strings.Cut(s, "=")
+	if !ok || reason == "" || expr == "" {
+		return BuildRule{}, fmt.Errorf("invalid build rule: %q", s)
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return BuildRule{}, fmt.Errorf("invalid build rule (%s): %w", reason, err)
+	}
+
+	return BuildRule{
+		Reason:  reason,
+		Pattern: pattern,
+	}, nil
+}
+
+// DefaultBuildRules is the default list of build rules
+var DefaultBuildRules = []BuildRule{
+	{
+		Reason:  "out_of_memory",
+		Pattern: regexp.MustCompile(`(?i)out of memory|OOMKilled|exit code:? 137|JavaScript heap out of memory|cannot allocate memory`),
+	},
+	{
+		Reason:  "timeout",
+		Pattern: regexp.MustCompile(`(?i)timed? ?out|deadline exceeded`),
+	},
+	{
+		Reason:  "dockerfile",
+		Pattern: regexp.MustCompile(`(?i)dockerfile parse error|failed to (?:solve|read) dockerfile|unknown instruction|failed to compute cache key|COPY failed`),
+	},
+	{
+		Reason:  "dependency_resolution",
+		Pattern: regexp.MustCompile(`(?i)could not resolve dependenc|unable to resolve dependency|ERESOLVE|no matching distribution found|could not find a version that satisfies|go: .*: (?:unknown revision|reading .*: 404)|failed to resolve|package .* not found`),
+	},
+}
+
+// buildKey is the set of label values by which failed builds are counted
+type buildKey struct {
+	serviceID string
+	reason    string
+}
+
+// BuildsCollector counts failed builds by the reason for which they failed
+// A failed build is classified once from its build log using rules; the first matching rule is the reason
+// At most buildClassifications failed builds are classified per scrape; the remainder are classified by subsequent scrapes
+type BuildsCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	rules []BuildRule
+
+	// State retained between scrapes
+	// primed is whether the failed Deployments listed by the first scrape have been recorded
+	// reasons is the reason of each classified failed build keyed by Deployment ID
+	// Deployments that failed before the first scrape are recorded without a reason and are not counted
+	// counts is the number of failed builds keyed by Service ID and reason
+	mu      sync.Mutex
+	primed  bool
+	reasons map[string]string
+	counts  map[buildKey]*counter
+
+	Failures *prometheus.Desc
+}
+
+// NewBuildsCollector is a function that creates a new BuildsCollector
+func NewBuildsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, rules []BuildRule) *BuildsCollector {
+	subsystem := "build"
+	logger := l.With("collector", subsystem)
+
+	return &BuildsCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		rules: rules,
+
+		reasons: map[string]string{},
+		counts:  map[buildKey]*counter{},
+
+		Failures: buildFailuresTotal.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *BuildsCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	deployments, err := listAll(func(limit, offset string) ([]koyeb.DeploymentListItem, bool, error) {
+		rqst := c.client.DeploymentsApi.ListDeployments(c.ctx).
+			Statuses([]string{
+				string(koyeb.DEPLOYMENTSTATUS_ERROR),
+			}).
+			Limit(limit).
+			Offset(offset)
+		resp, _, err := rqst.Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Deployments, resp.GetHasNext(), nil
+	})
+	if err != nil {
+		msg := "unable to list Deployments"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Deployments that are no longer listed are forgotten
+	current := map[string]string{}
+	classified := 0
+	deferred := 0
+
+	for _, deployment := range deployments {
+		id := deployment.GetId()
+		if reason, ok := c.reasons[id]; ok {
+			current[id] = reason
+			continue
+		}
+
+		// The first scrape records failed Deployments without classifying them so that historical failures are not counted
+		if !c.primed {
+			current[id] = ""
+			continue
+		}
+
+		messages, failed := failedBuild(deployment)
+		if !failed {
+			continue
+		}
+
+		if classified >= buildClassifications {
+			deferred++
+			continue
+		}
+		classified++
+
+		// Failed builds whose log cannot be fetched are classified during a subsequent scrape
+		lines, err := c.buildLog(id)
+		if err != nil {
+			logger.Info("unable to query build logs",
+				"deployment_id", id,
+				"err", err,
+			)
+			continue
+		}
+
+		reason := classifyBuild(c.rules, append(messages, lines...))
+		current[id] = reason
+
+		key := buildKey{
+			serviceID: deployment.GetServiceId(),
+			reason:    reason,
+		}
+		if _, ok := c.counts[key]; !ok {
+			c.counts[key] = newCounter(time.Now())
+		}
+		c.counts[key].inc(prometheus.Labels{"deployment_id": id}, deployment.GetUpdatedAt())
+	}
+
+	if deferred > 0 {
+		logger.Info("failed builds deferred to a subsequent scrape",
+			"classified", classified,
+			"deferred", deferred,
+		)
+	}
+
+	c.primed = true
+	c.reasons = current
+
+	for key, value := range c.counts {
+		ch <- value.metric(
+			c.Failures,
+			[]string{
+				key.serviceID,
+				key.reason,
+			}...,
+		)
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *BuildsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Failures
+}
+
+// buildLog is a method that returns the most recent lines of the Deployment's build log
+func (c *BuildsCollector) buildLog(deploymentID string) ([]string, error) {
+	rqst := c.client.LogsApi.QueryLogs(c.ctx).
+		Type_("build").
+		DeploymentId(deploymentID).
+		Order("desc").
+		Limit(strconv.Itoa(buildLogLines))
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(resp.Data))
+	for _, entry := range resp.Data {
+		lines = append(lines, entry.GetMsg())
+	}
+	return lines, nil
+}
+
+// failedBuild is a function that determines whether the Deployment's build stage failed
+// Returns the messages of the failed stage
+func failedBuild(deployment koyeb.DeploymentListItem) ([]string, bool) {
+	info := deployment.GetProvisioningInfo()
+	for _, stage := range info.Stages {
+		if !strings.Contains(strings.ToLower(stage.GetName()), "build") {
+			continue
+		}
+		if stage.GetStatus() == koyeb.DEPLOYMENTPROVISIONINGINFOSTAGESTATUS_FAILED {
+			return stage.Messages, true
+		}
+	}
+	return nil, false
+}
+
+// classifyBuild is a function that returns the reason of the first rule that matches any of the lines
+func classifyBuild(rules []BuildRule, lines []string) string {
+	for _, rule := range rules {
+		for _, line := range lines {
+			if rule.Pattern.MatchString(line) {
+				return rule.Reason
+			}
+		}
+	}
+	return UnknownBuildFailure
+}
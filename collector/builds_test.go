@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestParseBuildRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		reason  string
+		wantErr bool
+	}{
+		{rule: "registry=rate limit exceeded", reason: "registry"},
+		{rule: `disk=(?i)no space left=on device`, reason: "disk"},
+		{rule: "registry", wantErr: true},
+		{rule: "=rate limit exceeded", wantErr: true},
+		{rule: "registry=", wantErr: true},
+		{rule: "registry=(", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			rule, err := ParseBuildRule(test.rule)
+			if test.wantErr {
+				if err == nil {
+					t.Error("got nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rule.Reason != test.reason {
+				t.Errorf("got reason %q, want %q", rule.Reason, test.reason)
+			}
+		})
+	}
+}
+
+// newTestDeployment is a function that returns a Deployment decoded from the Koyeb API's JSON
+func newTestDeployment(t *testing.T, s string) koyeb.DeploymentListItem {
+	t.Helper()
+
+	deployment := koyeb.DeploymentListItem{}
+	if err := json.Unmarshal([]byte(s), &deployment); err != nil {
+		t.Fatal(err)
+	}
+	return deployment
+}
+
+func TestFailedBuild(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment string
+		messages   []string
+		want       bool
+	}{
+		{
+			name:       "build failed",
+			deployment: `{"id":"d1","provisioning_info":{"stages":[{"name":"BUILD","status":"FAILED","messages":["Build failed"]}]}}`,
+			messages:   []string{"Build failed"},
+			want:       true,
+		},
+		{
+			// Stages are matched by name regardless of case
+			name:       "docker build failed",
+			deployment: `{"id":"d2","provisioning_info":{"stages":[{"name":"fetch","status":"COMPLETED"},{"name":"docker-build","status":"FAILED"}]}}`,
+			want:       true,
+		},
+		{
+			name:       "build completed",
+			deployment: `{"id":"d3","provisioning_info":{"stages":[{"name":"BUILD","status":"COMPLETED"},{"name":"DEPLOY","status":"FAILED"}]}}`,
+		},
+		{
+			name:       "build aborted",
+			deployment: `{"id":"d4","provisioning_info":{"stages":[{"name":"BUILD","status":"ABORTED"}]}}`,
+		},
+		{
+			name:       "no provisioning info",
+			deployment: `{"id":"d5"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messages, ok := failedBuild(newTestDeployment(t, test.deployment))
+			if ok != test.want {
+				t.Errorf("got %t, want %t", ok, test.want)
+			}
+			if len(messages) != len(test.messages) {
+				t.Fatalf("got messages %v, want %v", messages, test.messages)
+			}
+			for i := range messages {
+				if messages[i] != test.messages[i] {
+					t.Errorf("got messages %v, want %v", messages, test.messages)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBuildsCollectorCollect(t *testing.T) {
+	// failed is the failed Deployments listed by the API and logs is the number of build logs fetched
+	var mu sync.Mutex
+	failed := []koyeb.DeploymentListItem{}
+	logs := 0
+
+	newFailed := func(id string) koyeb.DeploymentListItem {
+		return newTestDeployment(t, fmt.Sprintf(`{"id":%q,"service_id":"s1","status":"ERROR","provisioning_info":{"stages":[{"name":"BUILD","status":"FAILED"}]}}`, id))
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/deployments":
+			reply := koyeb.ListDeploymentsReply{}
+			reply.SetDeployments(failed)
+			writeJSON(t, w, reply)
+		case "/v1/streams/logs/query":
+			logs++
+
+			entry := koyeb.LogEntry{}
+			entry.SetMsg("Build timed out after 30m")
+
+			reply := koyeb.QueryLogsReply{}
+			reply.SetData([]koyeb.LogEntry{entry})
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	c := NewBuildsCollector(context.Background(), client, newTestStatus(t), newTestLogger(), DefaultBuildRules)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	key := buildKey{
+		serviceID: "s1",
+		reason:    "timeout",
+	}
+
+	scrapes := []struct {
+		name   string
+		failed int
+		want   float64
+		logs   int
+	}{
+		{
+			// Historical failures are recorded without fetching their build logs and are not counted
+			name:   "prime",
+			failed: 3,
+			want:   0,
+			logs:   0,
+		},
+		{
+			// At most buildClassifications failures are classified per scrape
+			name:   "bounded",
+			failed: 3 + buildClassifications + 2,
+			want:   float64(buildClassifications),
+			logs:   buildClassifications,
+		},
+		{
+			// The remainder are classified by the next scrape
+			name:   "deferred",
+			failed: 3 + buildClassifications + 2,
+			want:   float64(buildClassifications + 2),
+			logs:   buildClassifications + 2,
+		},
+	}
+	for _, scrape := range scrapes {
+		mu.Lock()
+		failed = failed[:0]
+		for i := range scrape.failed {
+			failed = append(failed, newFailed(fmt.Sprintf("d%d", i)))
+		}
+		mu.Unlock()
+
+		if _, err := registry.Gather(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := 0.0
+		if value, ok := c.counts[key]; ok {
+			got = value.value
+		}
+		if got != scrape.want {
+			t.Errorf("%s: got %v failures, want %v", scrape.name, got, scrape.want)
+		}
+
+		mu.Lock()
+		if logs != scrape.logs {
+			t.Errorf("%s: got %d build logs fetched, want %d", scrape.name, logs, scrape.logs)
+		}
+		mu.Unlock()
+	}
+
+	// Deployments that are no longer listed are forgotten
+	mu.Lock()
+	failed = failed[:1]
+	mu.Unlock()
+	if _, err := registry.Gather(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.reasons) != 1 {
+		t.Errorf("got %d recorded Deployments, want 1", len(c.reasons))
+	}
+}
+
+func TestClassifyBuild(t *testing.T) {
+	custom, err := ParseBuildRule("registry=rate limit exceeded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := append([]BuildRule{custom}, DefaultBuildRules...)
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{
+			name:  "out of memory",
+			lines: []string{"Step 5/9 : RUN npm run build", "FATAL ERROR: Reached heap limit Allocation failed - JavaScript heap out of memory"},
+			want:  "out_of_memory",
+		},
+		{
+			name:  "timeout",
+			lines: []string{"Build timed out after 30m"},
+			want:  "timeout",
+		},
+		{
+			name:  "dockerfile",
+			lines: []string{"failed to solve: dockerfile parse error on line 3: unknown instruction: RUNN"},
+			want:  "dockerfile",
+		},
+		{
+			name:  "dependency resolution",
+			lines: []string{"npm ERR! code ERESOLVE", "npm ERR! ERESOLVE unable to resolve dependency tree"},
+			want:  "dependency_resolution",
+		},
+		{
+			name:  "pip",
+			lines: []string{"ERROR: No matching distribution found for requests==99.0"},
+			want:  "dependency_resolution",
+		},
+		{
+			name:  "custom",
+			lines: []string{"toomanyrequests: rate limit exceeded"},
+			want:  "registry",
+		},
+		{
+			name:  "unknown",
+			lines: []string{"error: something else went wrong"},
+			want:  UnknownBuildFailure,
+		},
+		{
+			name: "empty",
+			want: UnknownBuildFailure,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := classifyBuild(rules, test.lines); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// counter is the state of a counter retained between scrapes
+// created is the time the counter was created and is exposed as the OpenMetrics "_created" sample
+// exemplar identifies the Koyeb resource (e.g. the event or Deployment) that most recently incremented the counter
+type counter struct {
+	value    float64
+	created  time.Time
+	exemplar *prometheus.Exemplar
+}
+
+// newCounter is a function that creates a new counter created at the given time
+func newCounter(created time.Time) *counter {
+	return &counter{
+		created: created,
+	}
+}
+
+// inc is a method that increments the counter and records the Koyeb resource that incremented it as an exemplar
+// labels may be nil in which case the counter's exemplar is unchanged
+func (c *counter) inc(labels prometheus.Labels, when time.Time) {
+	c.value++
+
+	if len(labels) == 0 {
+		return
+	}
+	c.exemplar = &prometheus.Exemplar{
+		Value:     1,
+		Labels:    labels,
+		Timestamp: when,
+	}
+}
+
+// metric is a method that returns the counter as a constant metric with its created timestamp and its exemplar (if any)
+func (c *counter) metric(desc *prometheus.Desc, labelValues ...string) prometheus.Metric {
+	m := prometheus.MustNewConstMetricWithCreatedTimestamp(
+		desc,
+		valueType(desc),
+		c.value,
+		c.created,
+		labelValues...,
+	)
+	if c.exemplar == nil {
+		return m
+	}
+	return prometheus.MustNewMetricWithExemplars(m, *c.exemplar)
+}
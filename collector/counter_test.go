@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCounterMetric(t *testing.T) {
+	desc := metric{
+		Subsystem: "test",
+		Name:      "total",
+		Type:      counterMetric,
+		Help:      "Test counter",
+		Labels: []string{
+			"service_id",
+		},
+	}.Desc()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	when := created.Add(time.Minute)
+
+	c := newCounter(created)
+	c.inc(nil, when)
+	c.inc(prometheus.Labels{"deployment_id": "d1"}, when)
+
+	m := &dto.Metric{}
+	if err := c.metric(desc, "s1").Write(m); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := m.GetCounter()
+	if got := counter.GetValue(); got != 2 {
+		t.Errorf("got value %v, want 2", got)
+	}
+	if got := counter.GetCreatedTimestamp().AsTime(); !got.Equal(created) {
+		t.Errorf("got created %s, want %s", got, created)
+	}
+
+	exemplar := counter.GetExemplar()
+	if exemplar == nil {
+		t.Fatal("got nil exemplar, want exemplar")
+	}
+	if got := exemplar.GetTimestamp().AsTime(); !got.Equal(when) {
+		t.Errorf("got exemplar timestamp %s, want %s", got, when)
+	}
+	labels := exemplar.GetLabel()
+	if len(labels) != 1 || labels[0].GetName() != "deployment_id" || labels[0].GetValue() != "d1" {
+		t.Errorf("got exemplar labels %v, want deployment_id=d1", labels)
+	}
+}
+
+func TestCounterMetricWithoutExemplar(t *testing.T) {
+	desc := metric{
+		Subsystem: "test",
+		Name:      "unlabeled_total",
+		Type:      counterMetric,
+		Help:      "Test counter",
+	}.Desc()
+
+	c := newCounter(time.Now())
+	c.inc(nil, time.Now())
+
+	m := &dto.Metric{}
+	if err := c.metric(desc).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetCounter().GetExemplar() != nil {
+		t.Error("got exemplar, want nil")
+	}
+}
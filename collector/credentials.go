@@ -19,11 +19,21 @@ type CredentialsCollector struct {
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// credential is the ID or name of the Credential whose API token the exporter uses
+	// The API does not return Credentials' tokens so the Credential can't be identified by token
+	credential string
+
+	Up              *prometheus.Desc
+	Info            *prometheus.Desc
+	ByUser          *prometheus.Desc
+	IsExporterToken *prometheus.Desc
+	Created         *prometheus.Desc
+	LastUpdated     *prometheus.Desc
 }
 
 // NewCredentialsCollector is a function that creates a new CredentialsCollector
-func NewCredentialsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *CredentialsCollector {
+// credential is the ID or name of the exporter's own Credential; if empty, no Credential is identified as the exporter's
+func NewCredentialsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, credential string) *CredentialsCollector {
 	subsystem := "credentials"
 	logger := l.With("collector", subsystem)
 
@@ -33,17 +43,14 @@ func NewCredentialsCollector(ctx context.Context, client *koyeb.APIClient, ch ch
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the Credentials is up, 0 otherwise",
-			[]string{
-				"id",
-				"organization_id",
-				"user_id",
-				"name",
-			},
-			nil,
-		),
+		credential: credential,
+
+		Up:              credentialUp.Desc(),
+		Info:            credentialInfo.Desc(),
+		ByUser:          credentialByUser.Desc(),
+		IsExporterToken: credentialIsExporterToken.Desc(),
+		Created:         credentialCreatedTimestampSeconds.Desc(),
+		LastUpdated:     credentialLastUpdatedTimestampSeconds.Desc(),
 	}
 }
 
@@ -75,10 +82,14 @@ func (c *CredentialsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 	c.ch <- status
 
+	// Number of Credentials keyed by the user that created them
+	users := map[string]int{}
+
 	for _, credential := range resp.Credentials {
-		ch <- prometheus.MustNewConstMetric(
+		users[credential.GetUserId()]++
+
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				credential.GetId(),
@@ -87,6 +98,61 @@ func (c *CredentialsCollector) Collect(ch chan<- prometheus.Metric) {
 				credential.GetName(),
 			}...,
 		)
+
+		ch <- constMetric(
+			c.Info,
+			1.0,
+			[]string{
+				credential.GetId(),
+				credential.GetName(),
+				string(credential.GetType()),
+				credential.GetUserId(),
+			}...,
+		)
+
+		isExporterToken := 0.0
+		if c.isExporterToken(credential) {
+			isExporterToken = 1.0
+		}
+		ch <- constMetric(
+			c.IsExporterToken,
+			isExporterToken,
+			[]string{
+				credential.GetId(),
+				credential.GetName(),
+			}...,
+		)
+
+		if credential.CreatedAt != nil {
+			ch <- constMetric(
+				c.Created,
+				float64(credential.GetCreatedAt().Unix()),
+				[]string{
+					credential.GetId(),
+					credential.GetName(),
+				}...,
+			)
+		}
+		if credential.UpdatedAt != nil {
+			ch <- constMetric(
+				c.LastUpdated,
+				float64(credential.GetUpdatedAt().Unix()),
+				[]string{
+					credential.GetId(),
+					credential.GetName(),
+				}...,
+			)
+		}
+	}
+
+	for userID, count := range users {
+		ch <- constMetric(
+			c.ByUser,
+			float64(count),
+			[]string{
+				userID,
+			}...,
+		)
 	}
 
 }
@@ -94,4 +160,17 @@ func (c *CredentialsCollector) Collect(ch chan<- prometheus.Metric) {
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *CredentialsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.Info
+	ch <- c.ByUser
+	ch <- c.IsExporterToken
+	ch <- c.Created
+	ch <- c.LastUpdated
+}
+
+// isExporterToken is a method that determines whether the Credential is the exporter's own Credential by its ID or name
+func (c *CredentialsCollector) isExporterToken(credential koyeb.Credential) bool {
+	if c.credential == "" {
+		return false
+	}
+	return credential.GetId() == c.credential || credential.GetName() == c.credential
 }
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+func TestCredentialsCollectorIsExporterToken(t *testing.T) {
+	credential := koyeb.Credential{}
+	credential.SetId("c1")
+	credential.SetName("exporter")
+
+	tests := []struct {
+		name       string
+		credential string
+		want       bool
+	}{
+		{
+			name:       "id",
+			credential: "c1",
+			want:       true,
+		},
+		{
+			name:       "name",
+			credential: "exporter",
+			want:       true,
+		},
+		{
+			name:       "other",
+			credential: "c2",
+			want:       false,
+		},
+		{
+			// Without --exporter_credential no Credential is the exporter's
+			name:       "unset",
+			credential: "",
+			want:       false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCredentialsCollector(context.Background(), nil, newTestStatus(t), newTestLogger(), test.credential)
+			if got := c.isExporterToken(credential); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
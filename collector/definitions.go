@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+// secretInterpolation matches references to Secrets interpolated in environment variable values e.g. "{{ secret.NAME }}"
+var secretInterpolation = regexp.MustCompile(`{{\s*secret\.([^\s}]+)\s*}}`)
+
+// activeDeployment pairs a Service with its active Deployment
+type activeDeployment struct {
+	Service    koyeb.ServiceListItem
+	Deployment koyeb.Deployment
+}
+
+// Definition is a method that returns the active Deployment's definition
+// Returns an empty definition if the Deployment has none
+func (a activeDeployment) Definition() koyeb.DeploymentDefinition {
+	return a.Deployment.GetDefinition()
+}
+
+// ActiveDeployments lists the active Deployment of each Service
+// Collectors share the list and it is retained for a TTL so that a scrape lists Services' active Deployments once rather than once per collector
+type ActiveDeployments struct {
+	client *koyeb.APIClient
+	logger *slog.Logger
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	listed  time.Time
+	actives []activeDeployment
+}
+
+// NewActiveDeployments is a function that creates a new ActiveDeployments
+// The list is retained for ttl; if ttl is 0, the active Deployments are listed every time
+func NewActiveDeployments(client *koyeb.APIClient, l *slog.Logger, ttl time.Duration) *ActiveDeployments {
+	return &ActiveDeployments{
+		client: client,
+		logger: l.With("cache", "active_deployments"),
+		ttl:    ttl,
+	}
+}
+
+// list is a method that returns the active Deployment of each Service
+// Concurrent callers wait for a single list of the active Deployments
+// Errors are not retained so that the next call lists the active Deployments again
+// Callers must not modify the returned active Deployments
+func (a *ActiveDeployments) list(ctx context.Context) ([]activeDeployment, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.actives != nil && time.Since(a.listed) < a.ttl {
+		return a.actives, nil
+	}
+
+	actives, err := listActiveDeployments(ctx, a.client, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	a.actives = actives
+	a.listed = time.Now()
+
+	return actives, nil
+}
+
+// listActiveDeployments is a function that returns the active Deployment of each Service
+// Services without an active Deployment are omitted
+// Services whose active Deployment can't be retrieved are logged and omitted
+func listActiveDeployments(ctx context.Context, client *koyeb.APIClient, logger *slog.Logger) ([]activeDeployment, error) {
+	services, err := listServices(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list Services: %w", err)
+	}
+
+	actives := []activeDeployment{}
+	for _, service := range services {
+		id := service.GetActiveDeploymentId()
+		if id == "" {
+			continue
+		}
+
+		resp, _, err := client.DeploymentsApi.GetDeployment(ctx, id).Execute()
+		if err != nil {
+			logger.Info("unable to get Deployment", "service", service.GetId(), "deployment", id, "err", err)
+			continue
+		}
+
+		actives = append(actives, activeDeployment{
+			Service:    service,
+			Deployment: resp.GetDeployment(),
+		})
+	}
+
+	return actives, nil
+}
+
+// referencedSecrets is a function that returns the names of the Secrets referenced by a Deployment definition
+// Secrets are referenced by environment variables (directly or interpolated in values) and as Docker registry credentials
+func referencedSecrets(definition koyeb.DeploymentDefinition) map[string]bool {
+	names := map[string]bool{}
+
+	for _, env := range definition.Env {
+		if name := env.GetSecret(); name != "" {
+			names[name] = true
+		}
+		for _, match := range secretInterpolation.FindAllStringSubmatch(env.GetValue(), -1) {
+			names[match[1]] = true
+		}
+	}
+
+	if docker, ok := definition.GetDockerOk(); ok {
+		if name := docker.GetImageRegistrySecret(); name != "" {
+			names[name] = true
+		}
+	}
+
+	return names
+}
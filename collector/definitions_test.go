@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+// newTestServices is a function that returns a Koyeb API client that lists count Services (with active Deployments) by page
+// Returns the number of requests to list Services
+func newTestServices(t *testing.T, count int) (*koyeb.APIClient, *atomic.Int32) {
+	t.Helper()
+
+	requests := &atomic.Int32{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/services":
+			requests.Add(1)
+
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			if limit == 0 {
+				t.Error("Services listed without a limit")
+				limit = count
+			}
+
+			services := []koyeb.ServiceListItem{}
+			for i := offset; i < min(offset+limit, count); i++ {
+				service := koyeb.ServiceListItem{}
+				service.SetId(fmt.Sprintf("s%d", i))
+				service.SetActiveDeploymentId(fmt.Sprintf("d%d", i))
+				services = append(services, service)
+			}
+
+			reply := koyeb.ListServicesReply{}
+			reply.SetServices(services)
+			reply.SetHasNext(offset+limit < count)
+			writeJSON(t, w, reply)
+		case strings.HasPrefix(r.URL.Path, "/v1/deployments/"):
+			deployment := koyeb.Deployment{}
+			deployment.SetId(strings.TrimPrefix(r.URL.Path, "/v1/deployments/"))
+
+			reply := koyeb.GetDeploymentReply{}
+			reply.SetDeployment(deployment)
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return client, requests
+}
+
+func TestListActiveDeployments(t *testing.T) {
+	// More Services than fit on a page
+	count := pageSize + 1
+	client, _ := newTestServices(t, count)
+
+	actives, err := listActiveDeployments(context.Background(), client, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actives) != count {
+		t.Fatalf("got %d active Deployments, want %d", len(actives), count)
+	}
+
+	last := actives[count-1]
+	if got, want := last.Deployment.GetId(), fmt.Sprintf("d%d", count-1); got != want {
+		t.Errorf("got Deployment %q, want %q", got, want)
+	}
+}
+
+func TestActiveDeploymentsList(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int32
+	}{
+		{
+			name: "retained",
+			ttl:  time.Hour,
+			want: 1,
+		},
+		{
+			name: "not retained",
+			ttl:  0,
+			want: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, requests := newTestServices(t, 2)
+			a := NewActiveDeployments(client, newTestLogger(), test.ttl)
+
+			for range 3 {
+				actives, err := a.list(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(actives) != 2 {
+					t.Errorf("got %d active Deployments, want 2", len(actives))
+				}
+			}
+
+			if got := requests.Load(); got != test.want {
+				t.Errorf("got %d requests to list Services, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestListActiveDeploymentsSkipsFailures(t *testing.T) {
+	// The active Deployment of Service s1 can't be retrieved
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/services":
+			services := []koyeb.ServiceListItem{}
+			for i := range 2 {
+				service := koyeb.ServiceListItem{}
+				service.SetId(fmt.Sprintf("s%d", i))
+				service.SetActiveDeploymentId(fmt.Sprintf("d%d", i))
+				services = append(services, service)
+			}
+
+			reply := koyeb.ListServicesReply{}
+			reply.SetServices(services)
+			writeJSON(t, w, reply)
+		case r.URL.Path == "/v1/deployments/d1":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/v1/deployments/"):
+			deployment := koyeb.Deployment{}
+			deployment.SetId(strings.TrimPrefix(r.URL.Path, "/v1/deployments/"))
+
+			reply := koyeb.GetDeploymentReply{}
+			reply.SetDeployment(deployment)
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	actives, err := listActiveDeployments(context.Background(), client, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actives) != 1 {
+		t.Fatalf("got %d active Deployments, want 1", len(actives))
+	}
+	if got := actives[0].Service.GetId(); got != "s0" {
+		t.Errorf("got Service %q, want %q", got, "s0")
+	}
+}
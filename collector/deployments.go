@@ -3,6 +3,7 @@ package collector
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/DazWilkin/go-probe/probe"
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
@@ -19,11 +20,16 @@ type DeploymentsCollector struct {
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	Up         *prometheus.Desc
+	SourceInfo *prometheus.Desc
+	ImageAge   *prometheus.Desc
 }
 
 // NewDeploymentsCollector is a function that creates a new DeploymentsCollector
-func NewDeploymentsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *DeploymentsCollector {
+func NewDeploymentsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments) *DeploymentsCollector {
 	subsystem := "deployments"
 	logger := l.With("collector", subsystem)
 
@@ -33,20 +39,11 @@ func NewDeploymentsCollector(ctx context.Context, client *koyeb.APIClient, ch ch
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the Deployment is up, 0 otherwise",
-			[]string{
-				"id",
-				"app_id",
-				"deployment_group",
-				"name",
-				"service_id",
-				"status",
-				"type",
-			},
-			nil,
-		),
+		actives: actives,
+
+		Up:         deploymentUp.Desc(),
+		SourceInfo: deploymentSourceInfo.Desc(),
+		ImageAge:   deploymentImageAgeSeconds.Desc(),
 	}
 }
 
@@ -79,9 +76,8 @@ func (c *DeploymentsCollector) Collect(ch chan<- prometheus.Metric) {
 	c.ch <- status
 
 	for _, deployment := range resp.Deployments {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				deployment.GetId(),
@@ -95,9 +91,54 @@ func (c *DeploymentsCollector) Collect(ch chan<- prometheus.Metric) {
 		)
 	}
 
+	c.collectSources(ch)
 }
 
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *DeploymentsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.SourceInfo
+	ch <- c.ImageAge
+}
+
+// collectSources is a method that collects the sources of Services' active Deployments
+func (c *DeploymentsCollector) collectSources(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collectSources")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		logger.Error("unable to list active Deployments", "err", err)
+		return
+	}
+
+	now := time.Now()
+	for _, active := range actives {
+		serviceID := active.Service.GetId()
+		s := deploymentSource(active.Deployment)
+
+		ch <- constMetric(
+			c.SourceInfo,
+			1.0,
+			[]string{
+				serviceID,
+				s.sourceType,
+				s.image,
+				s.tag,
+				s.repository,
+				s.branch,
+				s.sha,
+			}...,
+		)
+
+		if t, ok := tagTime(s.tag); ok {
+			ch <- constMetric(
+				c.ImageAge,
+				now.Sub(t).Seconds(),
+				[]string{
+					serviceID,
+					s.tag,
+				}...,
+			)
+		}
+	}
 }
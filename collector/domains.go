@@ -3,6 +3,9 @@ package collector
 import (
 	"context"
 	"log/slog"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/DazWilkin/go-probe/probe"
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
@@ -19,11 +22,26 @@ type DomainsCollector struct {
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	// Whether (custom) Domains' TLS certificates are probed and the timeout for each probe
+	tlsProbe   bool
+	tlsTimeout time.Duration
+
+	Up                 *prometheus.Desc
+	Verified           *prometheus.Desc
+	Verification       *prometheus.Desc
+	VerifiedTimestamp  *prometheus.Desc
+	CertificateExpiry  *prometheus.Desc
+	SecondsUntilExpiry *prometheus.Desc
+	RouteInfo          *prometheus.Desc
+	Routes             *prometheus.Desc
 }
 
 // NewDomainsCollector is a function that creates a new DomainsCollector
-func NewDomainsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *DomainsCollector {
+// When tlsProbe is true, the TLS certificate served by each custom Domain is probed to determine its expiry
+func NewDomainsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments, tlsProbe bool, tlsTimeout time.Duration) *DomainsCollector {
 	subsystem := "domains"
 	logger := l.With("collector", subsystem)
 
@@ -33,19 +51,19 @@ func NewDomainsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<-
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the Domain is up, 0 otherwise",
-			[]string{
-				"id",
-				"app_id",
-				"organization_id",
-				"name",
-				"status",
-				"type",
-			},
-			nil,
-		),
+		actives: actives,
+
+		tlsProbe:   tlsProbe,
+		tlsTimeout: tlsTimeout,
+
+		Up:                 domainUp.Desc(),
+		Verified:           domainVerified.Desc(),
+		Verification:       domainVerificationInfo.Desc(),
+		VerifiedTimestamp:  domainVerifiedTimestampSeconds.Desc(),
+		CertificateExpiry:  domainCertificateExpiryTimestampSeconds.Desc(),
+		SecondsUntilExpiry: domainSecondsUntilExpiry.Desc(),
+		RouteInfo:          domainRouteInfo.Desc(),
+		Routes:             domainRoutes.Desc(),
 	}
 }
 
@@ -78,9 +96,8 @@ func (c *DomainsCollector) Collect(ch chan<- prometheus.Metric) {
 	c.ch <- status
 
 	for _, domain := range resp.Domains {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				domain.GetId(),
@@ -91,11 +108,158 @@ func (c *DomainsCollector) Collect(ch chan<- prometheus.Metric) {
 				string(domain.GetType()),
 			}...,
 		)
+
+		verified := 0.0
+		if domain.VerifiedAt != nil {
+			verified = 1.0
+		}
+		ch <- constMetric(
+			c.Verified,
+			verified,
+			[]string{
+				domain.GetId(),
+				domain.GetName(),
+				string(domain.GetType()),
+			}...,
+		)
+		ch <- constMetric(
+			c.Verification,
+			1.0,
+			[]string{
+				domain.GetId(),
+				domain.GetName(),
+				string(domain.GetStatus()),
+				domain.GetIntendedCname(),
+			}...,
+		)
+
+		if domain.VerifiedAt != nil {
+			ch <- constMetric(
+				c.VerifiedTimestamp,
+				float64(domain.GetVerifiedAt().Unix()),
+				[]string{
+					domain.GetId(),
+					domain.GetName(),
+				}...,
+			)
+		}
+	}
+
+	c.collectRoutes(ch, resp.Domains)
+
+	if c.tlsProbe {
+		c.probeCertificates(ch, resp.Domains)
+	}
+}
+
+// collectRoutes is a method that joins Domains with the routes of the active Deployments of their App's Services
+// A Domain attached to a deployment group only routes to Deployments in that group
+func (c *DomainsCollector) collectRoutes(ch chan<- prometheus.Metric, domains []koyeb.Domain) {
+	logger := c.logger.With("method", "collectRoutes")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		logger.Error("unable to list active Deployments", "err", err)
+		return
+	}
+
+	for _, domain := range domains {
+		appID := domain.GetAppId()
+		if appID == "" {
+			continue
+		}
+
+		routes := 0
+		for _, active := range actives {
+			if active.Service.GetAppId() != appID {
+				continue
+			}
+			if group := domain.GetDeploymentGroup(); group != "" && group != active.Deployment.GetDeploymentGroup() {
+				continue
+			}
+
+			for _, route := range active.Definition().Routes {
+				routes++
+				ch <- constMetric(
+					c.RouteInfo,
+					1.0,
+					[]string{
+						domain.GetName(),
+						appID,
+						active.Service.GetId(),
+						route.GetPath(),
+						strconv.FormatInt(route.GetPort(), 10),
+					}...,
+				)
+			}
+		}
+
+		ch <- constMetric(
+			c.Routes,
+			float64(routes),
+			[]string{
+				domain.GetName(),
+				appID,
+			}...,
+		)
 	}
+}
+
+// probeCertificates is a method that probes the TLS certificate served by each active custom Domain concurrently
+// Domains whose certificate cannot be probed are logged and omitted
+func (c *DomainsCollector) probeCertificates(ch chan<- prometheus.Metric, domains []koyeb.Domain) {
+	logger := c.logger.With("method", "probeCertificates")
 
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		if domain.GetType() != koyeb.DOMAINTYPE_CUSTOM || domain.GetStatus() != koyeb.DOMAINSTATUS_ACTIVE {
+			continue
+		}
+
+		wg.Add(1)
+		go func(domain koyeb.Domain) {
+			defer wg.Done()
+
+			notAfter, err := certificateExpiry(c.ctx, domain.GetName(), c.tlsTimeout)
+			if err != nil {
+				logger.Info("unable to probe TLS certificate",
+					"domain", domain.GetName(),
+					"err", err,
+				)
+				return
+			}
+
+			ch <- constMetric(
+				c.CertificateExpiry,
+				float64(notAfter.Unix()),
+				[]string{
+					domain.GetId(),
+					domain.GetName(),
+				}...,
+			)
+			ch <- constMetric(
+				c.SecondsUntilExpiry,
+				notAfter.Sub(now).Seconds(),
+				[]string{
+					domain.GetId(),
+					domain.GetName(),
+				}...,
+			)
+		}(domain)
+	}
+	wg.Wait()
 }
 
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *DomainsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.Verified
+	ch <- c.Verification
+	ch <- c.VerifiedTimestamp
+	ch <- c.CertificateExpiry
+	ch <- c.SecondsUntilExpiry
+	ch <- c.RouteInfo
+	ch <- c.Routes
 }
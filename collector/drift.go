@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/drift"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that DriftCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*DriftCollector)(nil)
+
+// DriftCollector compares desired Deployment definitions with the definitions of Services' active Deployments
+type DriftCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	// desired are the desired Deployment definitions keyed by App and Service name (see drift.Key)
+	desired map[string]drift.Desired
+
+	mu     sync.Mutex
+	report drift.Report
+
+	Drift *prometheus.Desc
+}
+
+// NewDriftCollector is a function that creates a new DriftCollector
+func NewDriftCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments, desired map[string]drift.Desired) *DriftCollector {
+	logger := l.With("collector", "drift")
+
+	return &DriftCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		actives: actives,
+
+		desired: desired,
+
+		Drift: serviceDrift.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *DriftCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		msg := "unable to list active Deployments"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Service names are only unique within an App
+	apps, err := listApps(c.ctx, c.client)
+	if err != nil {
+		msg := "unable to list Apps"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	// App names keyed by ID
+	names := map[string]string{}
+	for _, app := range apps {
+		names[app.GetId()] = app.GetName()
+	}
+
+	// Active Deployments keyed by App and Service name
+	live := map[string]activeDeployment{}
+	for _, active := range actives {
+		name, ok := names[active.Service.GetAppId()]
+		if !ok {
+			continue
+		}
+		live[drift.Key(name, active.Service.GetName())] = active
+	}
+
+	report := drift.Report{
+		CheckedAt: time.Now(),
+		Services:  []drift.Service{},
+	}
+
+	for key, desired := range c.desired {
+		name := desired.Definition.GetName()
+		service := drift.Service{
+			App:     desired.App,
+			Service: name,
+			Source:  desired.Source,
+		}
+
+		active, ok := live[key]
+		if !ok {
+			service.Drifted = true
+			service.Fields = []drift.Field{
+				{
+					Field:   drift.Missing,
+					Drifted: true,
+				},
+			}
+		} else {
+			fields, err := drift.Diff(desired, active.Definition())
+			if err != nil {
+				logger.Error("unable to compare definitions",
+					"app", desired.App,
+					"service", name,
+					"err", err,
+				)
+				continue
+			}
+
+			service.ServiceID = active.Service.GetId()
+			service.Fields = fields
+			for _, field := range fields {
+				if field.Drifted {
+					service.Drifted = true
+				}
+			}
+		}
+
+		for _, field := range service.Fields {
+			value := 0.0
+			if field.Drifted {
+				value = 1.0
+			}
+			ch <- constMetric(
+				c.Drift,
+				value,
+				[]string{
+					desired.App,
+					name,
+					field.Field,
+				}...,
+			)
+		}
+
+		report.Services = append(report.Services, service)
+	}
+
+	sort.Slice(report.Services, func(i, j int) bool {
+		if report.Services[i].App != report.Services[j].App {
+			return report.Services[i].App < report.Services[j].App
+		}
+		return report.Services[i].Service < report.Services[j].Service
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.report = report
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *DriftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Drift
+}
+
+// Report is a method that returns the drift report computed by the most recent collection
+func (c *DriftCollector) Report() drift.Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.report
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/drift"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDriftCollectorKeysByApp(t *testing.T) {
+	// Two Apps each with a Service named web whose active Deployments run different images
+	apps := map[string]string{
+		"a1": "shop",
+		"a2": "blog",
+	}
+	images := map[string]string{
+		"d1": "docker.io/koyeb/shop",
+		"d2": "docker.io/koyeb/blog",
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/apps":
+			items := []koyeb.AppListItem{}
+			for id, name := range apps {
+				app := koyeb.AppListItem{}
+				app.SetId(id)
+				app.SetName(name)
+				items = append(items, app)
+			}
+
+			reply := koyeb.ListAppsReply{}
+			reply.SetApps(items)
+			writeJSON(t, w, reply)
+		case r.URL.Path == "/v1/services":
+			items := []koyeb.ServiceListItem{}
+			for _, i := range []string{"1", "2"} {
+				service := koyeb.ServiceListItem{}
+				service.SetId("s" + i)
+				service.SetAppId("a" + i)
+				service.SetName("web")
+				service.SetActiveDeploymentId("d" + i)
+				items = append(items, service)
+			}
+
+			reply := koyeb.ListServicesReply{}
+			reply.SetServices(items)
+			writeJSON(t, w, reply)
+		case strings.HasPrefix(r.URL.Path, "/v1/deployments/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v1/deployments/")
+
+			docker := koyeb.DockerSource{}
+			docker.SetImage(images[id])
+			definition := koyeb.DeploymentDefinition{}
+			definition.SetName("web")
+			definition.SetDocker(docker)
+			deployment := koyeb.Deployment{}
+			deployment.SetId(id)
+			deployment.SetDefinition(definition)
+
+			reply := koyeb.GetDeploymentReply{}
+			reply.SetDeployment(deployment)
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	// The desired definitions match the live definitions
+	dir := t.TempDir()
+	for app, content := range map[string]string{
+		"shop": "name: web\ndocker:\n  image: docker.io/koyeb/shop\n",
+		"blog": "name: web\ndocker:\n  image: docker.io/koyeb/blog\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, app), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, app, "web.yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	desired, err := drift.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actives := NewActiveDeployments(client, newTestLogger(), time.Minute)
+	c := NewDriftCollector(context.Background(), client, newTestStatus(t), newTestLogger(), actives, desired)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	if _, err := registry.Gather(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := c.Report()
+	if len(report.Services) != 2 {
+		t.Fatalf("got %d Services, want 2", len(report.Services))
+	}
+	for i, want := range []string{"blog", "shop"} {
+		service := report.Services[i]
+		if service.App != want {
+			t.Errorf("got App %q, want %q", service.App, want)
+		}
+		if service.Drifted {
+			t.Errorf("%s: got drifted %+v, want not drifted", drift.Key(service.App, service.Service), service.Fields)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that EnvCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*EnvCollector)(nil)
+
+// envVar identifies an environment variable, its kind and the Secret that it references (if any)
+type envVar struct {
+	name   string
+	kind   string
+	secret string
+}
+
+// EnvCollector collects the environment variables defined by each Service's active Deployment
+// Environment variable values are never exported
+type EnvCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	Info  *prometheus.Desc
+	Count *prometheus.Desc
+}
+
+// NewEnvCollector is a function that creates a new EnvCollector
+func NewEnvCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments) *EnvCollector {
+	logger := l.With("collector", "env")
+
+	return &EnvCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		actives: actives,
+
+		Info:  serviceEnvVarInfo.Desc(),
+		Count: serviceEnvVars.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *EnvCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		msg := "unable to list active Deployments"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	for _, active := range actives {
+		serviceID := active.Service.GetId()
+		vars := envVars(active.Definition())
+
+		// Number of distinct environment variable names keyed by kind
+		names := map[string]map[string]bool{
+			"plain":  {},
+			"secret": {},
+		}
+		for v := range vars {
+			names[v.kind][v.name] = true
+
+			ch <- constMetric(
+				c.Info,
+				1.0,
+				[]string{
+					serviceID,
+					v.name,
+					v.kind,
+					v.secret,
+				}...,
+			)
+		}
+
+		for kind, n := range names {
+			ch <- constMetric(
+				c.Count,
+				float64(len(n)),
+				[]string{
+					serviceID,
+					kind,
+				}...,
+			)
+		}
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *EnvCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Info
+	ch <- c.Count
+}
+
+// envVars is a function that returns the environment variables of a Deployment definition
+// An environment variable is a secret if it references a Secret directly or by interpolation in its value
+// An environment variable that interpolates several Secrets is returned once for each Secret
+// Environment variables defined for several scopes (e.g. regions) are returned once
+func envVars(definition koyeb.DeploymentDefinition) map[envVar]bool {
+	vars := map[envVar]bool{}
+
+	for _, env := range definition.Env {
+		name := env.GetKey()
+
+		secrets := []string{}
+		if secret := env.GetSecret(); secret != "" {
+			secrets = append(secrets, secret)
+		}
+		for _, match := range secretInterpolation.FindAllStringSubmatch(env.GetValue(), -1) {
+			secrets = append(secrets, match[1])
+		}
+
+		if len(secrets) == 0 {
+			vars[envVar{name: name, kind: "plain"}] = true
+			continue
+		}
+		for _, secret := range secrets {
+			vars[envVar{name: name, kind: "secret", secret: secret}] = true
+		}
+	}
+
+	return vars
+}
@@ -0,0 +1,455 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/types"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// eventsPageSize is the number of events requested per page
+	eventsPageSize int = 100
+	// eventsMaxPages bounds the number of pages read from a stream per scrape
+	eventsMaxPages int = 10
+	// eventsMaxResolved bounds the number of resolved resources retained between scrapes
+	eventsMaxResolved int = 10000
+)
+
+// Ensure that EventsCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*EventsCollector)(nil)
+
+// eventsKey is the set of label values by which events are counted
+type eventsKey struct {
+	eventType    string
+	resourceKind types.ResourceKind
+	appID        string
+	serviceID    string
+}
+
+// healthCheckKey is the set of label values by which failed health checks are counted
+type healthCheckKey struct {
+	serviceID string
+	port      string
+}
+
+// eventStream is a source of events and the cursor of the most recent event read from it
+type eventStream struct {
+	kind types.ResourceKind
+	// list returns a page of events ordered most recent first and whether there are more pages
+	list func(offset int) ([]types.Event, bool, error)
+
+	// primed is false until the stream has been read once
+	// cursor is the time of the most recent event read
+	// seen is the set of IDs of the events read whose time equals cursor
+	primed bool
+	cursor time.Time
+	seen   map[string]bool
+}
+
+// EventHandler is implemented by consumers of the events read by the EventsCollector
+type EventHandler interface {
+	// Handle is called with the events read during a scrape ordered oldest first by stream
+	// Handle is called during the scrape and must not block
+	Handle(ctx context.Context, events []types.Event)
+}
+
+// EventsCollector collects Koyeb Service, Deployment and Instance events and Organization activities
+type EventsCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	handler EventHandler
+
+	// State retained between scrapes
+	// apps is the App ID keyed by Service ID
+	// deployments and instances are the Service ID keyed by Deployment and Instance ID
+	mu          sync.Mutex
+	streams     []*eventStream
+	counts      map[eventsKey]*counter
+	failed      map[healthCheckKey]*counter
+	apps        map[string]string
+	deployments map[string]string
+	instances   map[string]string
+
+	Events             *prometheus.Desc
+	HealthCheckFailure *prometheus.Desc
+}
+
+// NewEventsCollector is a function that creates a new EventsCollector
+// handler may be nil in which case events are only counted
+func NewEventsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, handler EventHandler) *EventsCollector {
+	subsystem := "events"
+	logger := l.With("collector", subsystem)
+
+	c := &EventsCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		handler: handler,
+
+		counts:      map[eventsKey]*counter{},
+		failed:      map[healthCheckKey]*counter{},
+		apps:        map[string]string{},
+		deployments: map[string]string{},
+		instances:   map[string]string{},
+
+		Events:             eventsTotal.Desc(),
+		HealthCheckFailure: serviceHealthCheckFailuresTotal.Desc(),
+	}
+
+	c.streams = []*eventStream{
+		{
+			kind: types.ServiceResource,
+			list: c.listServiceEvents,
+		},
+		{
+			kind: types.DeploymentResource,
+			list: c.listDeploymentEvents,
+		},
+		{
+			kind: types.InstanceResource,
+			list: c.listInstanceEvents,
+		},
+		{
+			kind: types.OrganizationResource,
+			list: c.listActivities,
+		},
+	}
+
+	return c
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *EventsCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Events are read before resolving their Apps and Services so that resources created by the events are resolvable
+	// Historical events read when priming the streams are handled but not counted
+	// counted records whether the event with the same index is counted
+	events := []types.Event{}
+	counted := []bool{}
+	healthy := true
+	for _, stream := range c.streams {
+		e, primed, err := c.read(stream)
+		if err != nil {
+			logger.Error("unable to list events",
+				"resource_kind", stream.kind,
+				"err", err,
+			)
+			healthy = false
+			continue
+		}
+		events = append(events, e...)
+		for range e {
+			counted = append(counted, !primed)
+		}
+	}
+
+	if !healthy {
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: "unable to list events",
+		}
+		c.ch <- status
+	} else {
+		// Send probe healthy status
+		status := probe.Status{
+			Healthy: true,
+			Message: "ok",
+		}
+		c.ch <- status
+	}
+
+	if len(events) > 0 {
+		c.resolve(events)
+		if c.handler != nil {
+			c.handler.Handle(c.ctx, events)
+		}
+	}
+
+	now := time.Now()
+	for i, event := range events {
+		if !counted[i] {
+			continue
+		}
+
+		exemplar := prometheus.Labels{
+			"event_id": event.ID,
+		}
+
+		key := eventsKey{
+			eventType:    event.Type,
+			resourceKind: event.ResourceKind,
+			appID:        event.AppID,
+			serviceID:    event.ServiceID,
+		}
+		if _, ok := c.counts[key]; !ok {
+			c.counts[key] = newCounter(now)
+		}
+		c.counts[key].inc(exemplar, event.When)
+
+		if port, ok := healthCheckFailure(event); ok {
+			key := healthCheckKey{
+				serviceID: event.ServiceID,
+				port:      port,
+			}
+			if _, ok := c.failed[key]; !ok {
+				c.failed[key] = newCounter(now)
+			}
+			c.failed[key].inc(exemplar, event.When)
+		}
+	}
+
+	for key, value := range c.counts {
+		ch <- value.metric(
+			c.Events,
+			[]string{
+				key.eventType,
+				string(key.resourceKind),
+				key.appID,
+				key.serviceID,
+			}...,
+		)
+	}
+
+	for key, value := range c.failed {
+		ch <- value.metric(
+			c.HealthCheckFailure,
+			[]string{
+				key.serviceID,
+				key.port,
+			}...,
+		)
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *EventsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Events
+	ch <- c.HealthCheckFailure
+}
+
+// read is a method that returns the stream's events that are more recent than its cursor, ordered oldest first
+// The first read of a stream primes its cursor and returns the first page of (historical) events
+// Returns whether the read primed the stream so that historical events are not counted
+func (c *EventsCollector) read(stream *eventStream) ([]types.Event, bool, error) {
+	logger := c.logger.With("method", "read")
+
+	events := []types.Event{}
+
+	// Whether the event is more recent than the stream's cursor
+	unread := func(event types.Event) bool {
+		if event.When.After(stream.cursor) {
+			return true
+		}
+		return event.When.Equal(stream.cursor) && !stream.seen[event.ID]
+	}
+
+	offset := 0
+	done := false
+	for page := 0; page < eventsMaxPages && !done; page++ {
+		e, hasNext, err := stream.list(offset)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Only the first page is needed to prime the cursor
+		done = !hasNext || !stream.primed
+		for _, event := range e {
+			if stream.primed && !unread(event) {
+				done = true
+				break
+			}
+			events = append(events, event)
+		}
+
+		offset += len(e)
+	}
+
+	// Events older than those read are not read (and are not counted)
+	if !done {
+		logger.Warn("too many events; older events were not read",
+			"resource_kind", stream.kind,
+			"read", len(events),
+		)
+	}
+
+	primed := !stream.primed
+	stream.primed = true
+
+	if len(events) == 0 {
+		return events, primed, nil
+	}
+
+	// Advance the cursor to the most recent event
+	latest := events[0].When
+	if stream.seen == nil || !latest.Equal(stream.cursor) {
+		stream.cursor = latest
+		stream.seen = map[string]bool{}
+	}
+	for _, event := range events {
+		if event.When.Equal(latest) {
+			stream.seen[event.ID] = true
+		}
+	}
+
+	// Reverse the events so that they're ordered oldest first
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	return events, primed, nil
+}
+
+// resolve is a method that fills in the App and Service IDs of events from the Koyeb resources to which they refer
+// Resources are resolved once and retained between scrapes; only resources that have not been resolved are requested
+// Resources that cannot be requested (e.g. because they have been deleted) are left unresolved
+func (c *EventsCollector) resolve(events []types.Event) {
+	logger := c.logger.With("method", "resolve")
+
+	// Bound the resolved resources by forgetting them
+	for _, resolved := range []map[string]string{c.apps, c.deployments, c.instances} {
+		if len(resolved) > eventsMaxResolved {
+			clear(resolved)
+		}
+	}
+
+	// IDs of resources that cannot be requested during this scrape so that they're requested once
+	unresolved := map[string]bool{}
+
+	for i := range events {
+		event := &events[i]
+		id := event.ResourceID
+
+		switch event.ResourceKind {
+		case types.DeploymentResource:
+			if _, ok := c.deployments[id]; !ok && !unresolved[id] {
+				resp, _, err := c.client.DeploymentsApi.GetDeployment(c.ctx, id).Execute()
+				if err != nil {
+					logger.Info("unable to get Deployment", "deployment_id", id, "err", err)
+					unresolved[id] = true
+					break
+				}
+				deployment := resp.GetDeployment()
+				c.deployments[id] = deployment.GetServiceId()
+				c.apps[deployment.GetServiceId()] = deployment.GetAppId()
+			}
+			event.ServiceID = c.deployments[id]
+		case types.InstanceResource:
+			if _, ok := c.instances[id]; !ok && !unresolved[id] {
+				resp, _, err := c.client.InstancesApi.GetInstance(c.ctx, id).Execute()
+				if err != nil {
+					logger.Info("unable to get Instance", "instance_id", id, "err", err)
+					unresolved[id] = true
+					break
+				}
+				instance := resp.GetInstance()
+				c.instances[id] = instance.GetServiceId()
+				c.apps[instance.GetServiceId()] = instance.GetAppId()
+			}
+			event.ServiceID = c.instances[id]
+		}
+
+		if event.AppID != "" || event.ServiceID == "" {
+			continue
+		}
+
+		serviceID := event.ServiceID
+		if _, ok := c.apps[serviceID]; !ok && !unresolved[serviceID] {
+			resp, _, err := c.client.ServicesApi.GetService(c.ctx, serviceID).Execute()
+			if err != nil {
+				logger.Info("unable to get Service", "service_id", serviceID, "err", err)
+				unresolved[serviceID] = true
+				continue
+			}
+			service := resp.GetService()
+			c.apps[serviceID] = service.GetAppId()
+		}
+		event.AppID = c.apps[serviceID]
+	}
+}
+
+func (c *EventsCollector) listServiceEvents(offset int) ([]types.Event, bool, error) {
+	rqst := c.client.ServicesApi.ListServiceEvents(c.ctx).
+		Order("desc").
+		Limit(strconv.Itoa(eventsPageSize)).
+		Offset(strconv.Itoa(offset))
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]types.Event, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		events = append(events, types.FromServiceEvent(event))
+	}
+	return events, resp.GetHasNext(), nil
+}
+
+func (c *EventsCollector) listDeploymentEvents(offset int) ([]types.Event, bool, error) {
+	rqst := c.client.DeploymentsApi.ListDeploymentEvents(c.ctx).
+		Order("desc").
+		Limit(strconv.Itoa(eventsPageSize)).
+		Offset(strconv.Itoa(offset))
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]types.Event, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		events = append(events, types.FromDeploymentEvent(event))
+	}
+	return events, resp.GetHasNext(), nil
+}
+
+func (c *EventsCollector) listInstanceEvents(offset int) ([]types.Event, bool, error) {
+	rqst := c.client.InstancesApi.ListInstanceEvents(c.ctx).
+		Order("desc").
+		Limit(strconv.Itoa(eventsPageSize)).
+		Offset(strconv.Itoa(offset))
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]types.Event, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		events = append(events, types.FromInstanceEvent(event))
+	}
+	return events, resp.GetHasNext(), nil
+}
+
+// listActivities is a method that lists Organization activities
+// Activities are returned most recent first
+func (c *EventsCollector) listActivities(offset int) ([]types.Event, bool, error) {
+	rqst := c.client.ActivityApi.ListActivities(c.ctx).
+		Limit(strconv.Itoa(eventsPageSize)).
+		Offset(strconv.Itoa(offset))
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		return nil, false, err
+	}
+
+	events := make([]types.Event, 0, len(resp.Activities))
+	for _, activity := range resp.Activities {
+		events = append(events, types.FromActivity(activity))
+	}
+	return events, resp.GetHasNext(), nil
+}
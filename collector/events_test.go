@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newTestResolver is a function that returns a Koyeb API client that gets a single Deployment of a Service of an App
+// Returns the number of requests
+func newTestResolver(t *testing.T) (*koyeb.APIClient, *atomic.Int32) {
+	t.Helper()
+
+	requests := &atomic.Int32{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/v1/services/s1":
+			service := koyeb.Service{}
+			service.SetId("s1")
+			service.SetAppId("a1")
+
+			reply := koyeb.GetServiceReply{}
+			reply.SetService(service)
+			writeJSON(t, w, reply)
+		case "/v1/deployments/d1":
+			deployment := koyeb.Deployment{}
+			deployment.SetId("d1")
+			deployment.SetServiceId("s1")
+			deployment.SetAppId("a1")
+
+			reply := koyeb.GetDeploymentReply{}
+			reply.SetDeployment(deployment)
+			writeJSON(t, w, reply)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return client, requests
+}
+
+func TestEventsCollectorCountsOnce(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newEvent := func(id string, when time.Time) types.Event {
+		return types.Event{
+			ID:           id,
+			When:         when,
+			Type:         "deployment.healthy",
+			ResourceKind: types.DeploymentResource,
+			ResourceID:   "d1",
+		}
+	}
+
+	// feed is the stream's events ordered most recent first
+	feed := []types.Event{}
+
+	client, requests := newTestResolver(t)
+	c := NewEventsCollector(context.Background(), client, newTestStatus(t), newTestLogger(), nil)
+	c.streams = []*eventStream{
+		{
+			kind: types.DeploymentResource,
+			list: func(offset int) ([]types.Event, bool, error) {
+				end := min(offset+eventsPageSize, len(feed))
+				return feed[offset:end], end < len(feed), nil
+			},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	key := eventsKey{
+		eventType:    "deployment.healthy",
+		resourceKind: types.DeploymentResource,
+		appID:        "a1",
+		serviceID:    "s1",
+	}
+
+	scrapes := []struct {
+		name string
+		feed []types.Event
+		want float64
+	}{
+		{
+			// Historical events read when priming the stream are not counted
+			name: "prime",
+			feed: []types.Event{
+				newEvent("e1", start),
+			},
+			want: 0,
+		},
+		{
+			name: "new event",
+			feed: []types.Event{
+				newEvent("e2", start.Add(time.Minute)),
+				newEvent("e1", start),
+			},
+			want: 1,
+		},
+		{
+			// The same events read again are not counted again
+			name: "same events",
+			feed: []types.Event{
+				newEvent("e2", start.Add(time.Minute)),
+				newEvent("e1", start),
+			},
+			want: 1,
+		},
+		{
+			// A new event with the same time as the cursor is counted but the event at the cursor is not
+			name: "same time",
+			feed: []types.Event{
+				newEvent("e3", start.Add(time.Minute)),
+				newEvent("e2", start.Add(time.Minute)),
+				newEvent("e1", start),
+			},
+			want: 2,
+		},
+	}
+	for _, scrape := range scrapes {
+		feed = scrape.feed
+		if _, err := registry.Gather(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := 0.0
+		if value, ok := c.counts[key]; ok {
+			got = value.value
+		}
+		if got != scrape.want {
+			t.Errorf("%s: got %v events, want %v", scrape.name, got, scrape.want)
+		}
+	}
+
+	if len(c.counts) > 1 {
+		t.Errorf("got %d event keys, want 1 (events resolved to their App and Service)", len(c.counts))
+	}
+
+	// The Deployment is resolved once
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests to resolve events, want 1", got)
+	}
+}
@@ -20,43 +20,25 @@ type ExporterCollector struct {
 
 // NewExporterCollector returns a new ExporterCollector.
 func NewExporterCollector(osVersion, goVersion, gitCommit string, startTime int64) *ExporterCollector {
-	subsystem := "exporter"
-
 	return &ExporterCollector{
 		osVersion: osVersion,
 		goVersion: goVersion,
 		gitCommit: gitCommit,
 		startTime: startTime,
 
-		StartTime: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "start_time"),
-			"Exporter start time in Unix epoch seconds",
-			nil,
-			nil,
-		),
-		BuildInfo: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "build_info"),
-			"A metric with a constant '1' value labeled by OS version, Go version, and the Git commit of the exporter",
-			[]string{
-				"os_version",
-				"go_version",
-				"git_commit",
-			},
-			nil,
-		),
+		StartTime: exporterStartTimeSeconds.Desc(),
+		BuildInfo: exporterBuildInfo.Desc(),
 	}
 }
 
 // Collect implements Prometheus' Collector interface and is used to collect metrics
 func (c *ExporterCollector) Collect(ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(
+	ch <- constMetric(
 		c.StartTime,
-		prometheus.GaugeValue,
 		float64(c.startTime),
 	)
-	ch <- prometheus.MustNewConstMetric(
+	ch <- constMetric(
 		c.BuildInfo,
-		prometheus.CounterValue,
 		1.0,
 		[]string{
 			c.osVersion,
@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/types"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that HealthChecksCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*HealthChecksCollector)(nil)
+
+// HealthChecksCollector collects the health checks configured by each Service's active Deployment
+type HealthChecksCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	Info *prometheus.Desc
+}
+
+// NewHealthChecksCollector is a function that creates a new HealthChecksCollector
+func NewHealthChecksCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments) *HealthChecksCollector {
+	logger := l.With("collector", "health_checks")
+
+	return &HealthChecksCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		actives: actives,
+
+		Info: serviceHealthCheckInfo.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *HealthChecksCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		msg := "unable to list active Deployments"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	for _, active := range actives {
+		definition := active.Definition()
+		for _, check := range definition.GetHealthChecks() {
+			protocol, port, path := "", int64(0), ""
+			switch {
+			case check.Http != nil:
+				protocol = "http"
+				port = check.Http.GetPort()
+				path = check.Http.GetPath()
+			case check.Tcp != nil:
+				protocol = "tcp"
+				port = check.Tcp.GetPort()
+			}
+
+			ch <- constMetric(
+				c.Info,
+				1.0,
+				[]string{
+					active.Service.GetId(),
+					protocol,
+					strconv.FormatInt(port, 10),
+					path,
+					strconv.FormatInt(check.GetGracePeriod(), 10),
+					strconv.FormatInt(check.GetInterval(), 10),
+					strconv.FormatInt(check.GetRestartLimit(), 10),
+					strconv.FormatInt(check.GetTimeout(), 10),
+				}...,
+			)
+		}
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *HealthChecksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Info
+}
+
+// healthCheckFailure is a function that determines whether an Instance event reports a failed health check
+// Returns the port of the failed health check if the event's metadata includes it
+func healthCheckFailure(event types.Event) (string, bool) {
+	if event.ResourceKind != types.InstanceResource {
+		return "", false
+	}
+
+	t := strings.ToLower(event.Type)
+	if !strings.Contains(t, "health") {
+		return "", false
+	}
+	if !strings.Contains(t, "fail") && !strings.Contains(t, "unhealthy") {
+		return "", false
+	}
+
+	port := ""
+	if value, ok := event.Metadata["port"]; ok {
+		switch v := value.(type) {
+		case float64:
+			port = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			port = fmt.Sprint(v)
+		}
+	}
+	return port, true
+}
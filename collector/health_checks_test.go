@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+func TestHealthCheckFailure(t *testing.T) {
+	// Events are decoded from the Koyeb API's JSON so that their metadata has the types of real events
+	instanceEvent := func(t *testing.T, s string) types.Event {
+		t.Helper()
+
+		event := koyeb.InstanceEvent{}
+		if err := json.Unmarshal([]byte(s), &event); err != nil {
+			t.Fatal(err)
+		}
+		return types.FromInstanceEvent(event)
+	}
+	deploymentEvent := func(t *testing.T, s string) types.Event {
+		t.Helper()
+
+		event := koyeb.DeploymentEvent{}
+		if err := json.Unmarshal([]byte(s), &event); err != nil {
+			t.Fatal(err)
+		}
+		return types.FromDeploymentEvent(event)
+	}
+
+	tests := []struct {
+		name  string
+		event func(t *testing.T) types.Event
+		port  string
+		want  bool
+	}{
+		{
+			name: "failed with port",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e1","instance_id":"i1","type":"instance.healthcheck_failed","message":"TCP health check failed on port 8000","metadata":{"port":8000}}`)
+			},
+			port: "8000",
+			want: true,
+		},
+		{
+			name: "failed with port as string",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e2","instance_id":"i1","type":"INSTANCE.HEALTH_CHECK_FAILED","metadata":{"port":"8080"}}`)
+			},
+			port: "8080",
+			want: true,
+		},
+		{
+			name: "unhealthy without port",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e3","instance_id":"i1","type":"instance.unhealthy","message":"Instance is unhealthy"}`)
+			},
+			want: true,
+		},
+		{
+			name: "healthy",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e4","instance_id":"i1","type":"instance.healthy","message":"Instance is healthy"}`)
+			},
+		},
+		{
+			name: "failed without health check",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e5","instance_id":"i1","type":"instance.failed","metadata":{"reason":"OOMKilled"}}`)
+			},
+		},
+		{
+			// Only the event's type is matched and not its message
+			name: "message only",
+			event: func(t *testing.T) types.Event {
+				return instanceEvent(t, `{"id":"e6","instance_id":"i1","type":"instance.terminated","message":"health check failed"}`)
+			},
+		},
+		{
+			// Only Instance events report health checks
+			name: "deployment",
+			event: func(t *testing.T) types.Event {
+				return deploymentEvent(t, `{"id":"e7","deployment_id":"d1","type":"deployment.health_check_failed","metadata":{"port":8000}}`)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			port, ok := healthCheckFailure(test.event(t))
+			if ok != test.want {
+				t.Errorf("got %t, want %t", ok, test.want)
+			}
+			if port != test.port {
+				t.Errorf("got port %q, want %q", port, test.port)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxProbeBody bounds the number of bytes of a response body read by a probe
+const maxProbeBody int64 = 1 << 20
+
+// Ensure that HTTPProbesCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*HTTPProbesCollector)(nil)
+
+// endpoint is a public route of a Service's active Deployment on one of its App's Domains
+type endpoint struct {
+	url       string
+	appID     string
+	serviceID string
+}
+
+// probeResult is the result of probing an endpoint
+// statusCode is zero if the request failed
+// expiry is zero if the endpoint was not served over TLS
+type probeResult struct {
+	statusCode int
+	duration   time.Duration
+	expiry     time.Time
+	err        error
+}
+
+// HTTPProbesCollector probes the public routes of Services on their App's Domains
+// Endpoints are probed in the background on an interval; collections report the most recent results
+type HTTPProbesCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	httpClient *http.Client
+
+	// results are the most recent probe results keyed by endpoint
+	mu      sync.Mutex
+	results map[endpoint]probeResult
+
+	StatusCode        *prometheus.Desc
+	Success           *prometheus.Desc
+	CertificateExpiry *prometheus.Desc
+	Duration          *prometheus.HistogramVec
+}
+
+// NewHTTPProbesCollector is a function that creates a new HTTPProbesCollector
+// Each probe is bounded by timeout
+func NewHTTPProbesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments, timeout time.Duration) *HTTPProbesCollector {
+	subsystem := "http_probe"
+	logger := l.With("collector", subsystem)
+
+	return &HTTPProbesCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		actives: actives,
+
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+
+		results: map[endpoint]probeResult{},
+
+		StatusCode:        httpProbeStatusCode.Desc(),
+		Success:           httpProbeSuccess.Desc(),
+		CertificateExpiry: httpProbeCertificateExpiryTimestampSeconds.Desc(),
+		Duration: prometheus.NewHistogramVec(
+			httpProbeDuration.HistogramOpts(prometheus.DefBuckets),
+			httpProbeDuration.Labels,
+		),
+	}
+}
+
+// Run is a method that probes the endpoints immediately and then every interval until ctx is done
+func (c *HTTPProbesCollector) Run(ctx context.Context, interval time.Duration) {
+	logger := c.logger.With("method", "run")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		endpoints, err := c.endpoints()
+		if err != nil {
+			msg := "unable to determine endpoints"
+			logger.Error(msg, "err", err)
+
+			// Send probe unhealthy status
+			// Doesn't surface the API error message (should it!?)
+			status := probe.Status{
+				Healthy: false,
+				Message: msg,
+			}
+			c.ch <- status
+		} else {
+			// Send probe healthy status
+			status := probe.Status{
+				Healthy: true,
+				Message: "ok",
+			}
+			c.ch <- status
+
+			c.probeAll(ctx, endpoints)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *HTTPProbesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e, result := range c.results {
+		labels := []string{
+			e.url,
+			e.appID,
+			e.serviceID,
+		}
+
+		ch <- constMetric(
+			c.StatusCode,
+			float64(result.statusCode),
+			labels...,
+		)
+
+		success := 0.0
+		if result.statusCode >= 200 && result.statusCode < 400 {
+			success = 1.0
+		}
+		ch <- constMetric(
+			c.Success,
+			success,
+			labels...,
+		)
+
+		if !result.expiry.IsZero() {
+			ch <- constMetric(
+				c.CertificateExpiry,
+				float64(result.expiry.Unix()),
+				labels...,
+			)
+		}
+	}
+
+	c.Duration.Collect(ch)
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *HTTPProbesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.StatusCode
+	ch <- c.Success
+	ch <- c.CertificateExpiry
+	c.Duration.Describe(ch)
+}
+
+// endpoints is a method that joins active Domains with the routes of the active Deployments of their App's Services
+// A Domain attached to a deployment group only routes to Deployments in that group
+func (c *HTTPProbesCollector) endpoints() ([]endpoint, error) {
+	domains, err := listAll(func(limit, offset string) ([]koyeb.Domain, bool, error) {
+		resp, _, err := c.client.DomainsApi.ListDomains(c.ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.GetOffset()+int64(len(resp.Domains)) < resp.GetCount(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []endpoint{}
+	for _, domain := range domains {
+		appID := domain.GetAppId()
+		if appID == "" || domain.GetStatus() != koyeb.DOMAINSTATUS_ACTIVE {
+			continue
+		}
+
+		for _, active := range actives {
+			if active.Service.GetAppId() != appID {
+				continue
+			}
+			if group := domain.GetDeploymentGroup(); group != "" && group != active.Deployment.GetDeploymentGroup() {
+				continue
+			}
+
+			for _, route := range active.Definition().Routes {
+				endpoints = append(endpoints, endpoint{
+					url:       "https://" + domain.GetName() + route.GetPath(),
+					appID:     appID,
+					serviceID: active.Service.GetId(),
+				})
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+// probeAll is a method that probes the endpoints concurrently and records their results
+// Results of endpoints that are no longer listed are discarded
+func (c *HTTPProbesCollector) probeAll(ctx context.Context, endpoints []endpoint) {
+	results := make([]probeResult, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func(i int, e endpoint) {
+			defer wg.Done()
+			results[i] = probeEndpoint(ctx, c.httpClient, e.url)
+		}(i, e)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := map[endpoint]probeResult{}
+	for i, e := range endpoints {
+		result := results[i]
+		if result.err != nil {
+			c.logger.Info("unable to probe endpoint",
+				"endpoint", e.url,
+				"err", result.err,
+			)
+		}
+
+		current[e] = result
+		c.Duration.WithLabelValues(e.url, e.appID, e.serviceID).Observe(result.duration.Seconds())
+	}
+
+	for e := range c.results {
+		if _, ok := current[e]; !ok {
+			c.Duration.DeleteLabelValues(e.url, e.appID, e.serviceID)
+		}
+	}
+
+	c.results = current
+}
+
+// probeEndpoint is a function that GETs the URL and returns the response's status code, the request's duration and the expiry of the served TLS certificate
+func probeEndpoint(ctx context.Context, client *http.Client, url string) probeResult {
+	rqst, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return probeResult{err: err}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(rqst)
+	if err != nil {
+		return probeResult{
+			duration: time.Since(start),
+			err:      err,
+		}
+	}
+	defer resp.Body.Close()
+
+	// Include reading the body in the duration
+	_, err = io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+
+	result := probeResult{
+		statusCode: resp.StatusCode,
+		duration:   time.Since(start),
+		err:        err,
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.expiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+
+	return result
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestServer(t *testing.T, statusCode int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbeEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		statusCode int
+	}{
+		{
+			name:       "ok",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "unavailable",
+			statusCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t, test.statusCode)
+
+			result := probeEndpoint(ctx, server.Client(), server.URL+"/")
+			if result.err != nil {
+				t.Fatal(result.err)
+			}
+			if result.statusCode != test.statusCode {
+				t.Errorf("got status code %d, want %d", result.statusCode, test.statusCode)
+			}
+			if result.duration <= 0 {
+				t.Errorf("got duration %s, want > 0", result.duration)
+			}
+			if want := server.Certificate().NotAfter; !result.expiry.Equal(want) {
+				t.Errorf("got expiry %s, want %s", result.expiry, want)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := newTestServer(t, http.StatusOK)
+		client := server.Client()
+		url := server.URL
+		server.Close()
+
+		result := probeEndpoint(ctx, client, url)
+		if result.err == nil {
+			t.Error("got nil error, want error")
+		}
+		if result.statusCode != 0 {
+			t.Errorf("got status code %d, want 0", result.statusCode)
+		}
+	})
+}
+
+func TestHTTPProbesCollector(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	server := newTestServer(t, http.StatusOK)
+
+	c := NewHTTPProbesCollector(ctx, nil, nil, logger, nil, 5*time.Second)
+	c.httpClient = server.Client()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	e := endpoint{
+		url:       server.URL + "/",
+		appID:     "app",
+		serviceID: "service",
+	}
+	c.probeAll(ctx, []endpoint{e})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			switch {
+			case metric.GetGauge() != nil:
+				values[family.GetName()] = metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				values[family.GetName()] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	want := map[string]float64{
+		"koyeb_http_probe_status_code":                          http.StatusOK,
+		"koyeb_http_probe_success":                              1,
+		"koyeb_http_probe_certificate_expiry_timestamp_seconds": float64(server.Certificate().NotAfter.Unix()),
+		"koyeb_http_probe_duration_seconds":                     1,
+	}
+	for name, value := range want {
+		got, ok := values[name]
+		if !ok {
+			t.Errorf("missing metric %q", name)
+			continue
+		}
+		if got != value {
+			t.Errorf("metric %q: got %v, want %v", name, got, value)
+		}
+	}
+
+	// Endpoints that are no longer listed are no longer reported
+	c.probeAll(ctx, []endpoint{})
+
+	families, err = registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 0 {
+		t.Errorf("got %d metric families, want 0", len(families))
+	}
+}
@@ -2,9 +2,13 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/catalog"
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -12,18 +16,44 @@ import (
 // Ensure that InstancesCollector implements Prometheus' Collector interface
 var _ prometheus.Collector = (*InstancesCollector)(nil)
 
-// InstancesCollector collects Koyeb Apps metrics
+// InstancesCollector collects Koyeb Instances metrics
 type InstancesCollector struct {
 	ctx    context.Context
 	client *koyeb.APIClient
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// catalog provides the prices of instance types
+	catalog *catalog.Catalog
+
+	// Crash-loop policy
+	threshold int
+	window    time.Duration
+
+	// State retained between scrapes
+	// slots maps a replica slot (regional deployment and replica index) to its most recent Instance ID
+	// replacements counts Instance replacements by Service ID
+	// history records the time of each Instance replacement by Service ID
+	// terminations counts Instance terminations by Service ID and reason
+	mu           sync.Mutex
+	slots        map[string]string
+	replacements map[string]*counter
+	history      map[string][]time.Time
+	terminations map[string]map[string]*counter
+
+	Up           *prometheus.Desc
+	Age          *prometheus.Desc
+	Replacements *prometheus.Desc
+	Terminations *prometheus.Desc
+	CrashLoop    *prometheus.Desc
+	CostPerHour  *prometheus.Desc
+	AppCost      *prometheus.Desc
 }
 
 // NewInstancesCollector is a function that creates a new InstancesCollector
-func NewInstancesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *InstancesCollector {
+// A Service is considered to be crash-looping when its Instances are replaced more than threshold times within window
+// The cost of Instances is determined from the prices of their instance types in the catalog
+func NewInstancesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, catalog *catalog.Catalog, threshold int, window time.Duration) *InstancesCollector {
 	subsystem := "instances"
 	logger := l.With("collector", subsystem)
 
@@ -33,19 +63,23 @@ func NewInstancesCollector(ctx context.Context, client *koyeb.APIClient, ch chan
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the Instance is up, 0 otherwise",
-			[]string{
-				"id",
-				"app_id",
-				"service_id",
-				"organization_id",
-				"region",
-				"status",
-			},
-			nil,
-		),
+		catalog: catalog,
+
+		threshold: threshold,
+		window:    window,
+
+		slots:        map[string]string{},
+		replacements: map[string]*counter{},
+		history:      map[string][]time.Time{},
+		terminations: map[string]map[string]*counter{},
+
+		Up:           instanceUp.Desc(),
+		Age:          instanceAgeSeconds.Desc(),
+		Replacements: serviceInstanceReplacementsTotal.Desc(),
+		Terminations: serviceInstanceTerminationsTotal.Desc(),
+		CrashLoop:    serviceCrashLoop.Desc(),
+		CostPerHour:  instanceCostPerHour.Desc(),
+		AppCost:      appEstimatedMonthlyCost.Desc(),
 	}
 }
 
@@ -53,8 +87,7 @@ func NewInstancesCollector(ctx context.Context, client *koyeb.APIClient, ch chan
 func (c *InstancesCollector) Collect(ch chan<- prometheus.Metric) {
 	logger := c.logger.With("method", "collect")
 
-	rqst := c.client.InstancesApi.ListInstances(c.ctx)
-	resp, _, err := rqst.Execute()
+	instances, err := listInstances(c.ctx, c.client)
 	if err != nil {
 		msg := "unable to list Instances"
 		logger.Error(msg, "err", err)
@@ -77,10 +110,11 @@ func (c *InstancesCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 	c.ch <- status
 
-	for _, instance := range resp.Instances {
-		ch <- prometheus.MustNewConstMetric(
+	now := time.Now()
+
+	for _, instance := range instances {
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				instance.GetId(),
@@ -91,11 +125,230 @@ func (c *InstancesCollector) Collect(ch chan<- prometheus.Metric) {
 				string(instance.GetStatus()),
 			}...,
 		)
+
+		if instance.CreatedAt != nil {
+			ch <- constMetric(
+				c.Age,
+				now.Sub(instance.GetCreatedAt()).Seconds(),
+				[]string{
+					instance.GetId(),
+					instance.GetServiceId(),
+				}...,
+			)
+		}
 	}
 
+	c.collectCosts(ch, instances)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	replaced := c.update(instances, now)
+	if len(replaced) > 0 {
+		c.terminate(replaced, now)
+	}
+
+	for serviceID, value := range c.replacements {
+		ch <- value.metric(
+			c.Replacements,
+			[]string{
+				serviceID,
+			}...,
+		)
+
+		crashLoop := 0.0
+		if len(c.history[serviceID]) > c.threshold {
+			crashLoop = 1.0
+		}
+		ch <- constMetric(
+			c.CrashLoop,
+			crashLoop,
+			[]string{
+				serviceID,
+			}...,
+		)
+	}
+
+	for serviceID, reasons := range c.terminations {
+		for reason, value := range reasons {
+			ch <- value.metric(
+				c.Terminations,
+				[]string{
+					serviceID,
+					reason,
+				}...,
+			)
+		}
+	}
 }
 
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *InstancesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.Age
+	ch <- c.Replacements
+	ch <- c.Terminations
+	ch <- c.CrashLoop
+	ch <- c.CostPerHour
+	ch <- c.AppCost
+}
+
+// collectCosts is a method that joins running Instances with the catalog to determine their costs
+// Instances whose instance type is not in the catalog are logged and omitted
+func (c *InstancesCollector) collectCosts(ch chan<- prometheus.Metric, instances []koyeb.InstanceListItem) {
+	logger := c.logger.With("method", "collectCosts")
+
+	// Hourly cost keyed by App ID
+	apps := map[string]float64{}
+	for _, instance := range instances {
+		if !running(instance.GetStatus()) {
+			continue
+		}
+
+		instanceType, ok := c.catalog.Lookup(instance.GetType())
+		if !ok {
+			logger.Info("unable to find instance type in catalog", "type", instance.GetType())
+			continue
+		}
+
+		apps[instance.GetAppId()] += instanceType.PriceHourly
+
+		ch <- constMetric(
+			c.CostPerHour,
+			instanceType.PriceHourly,
+			[]string{
+				instance.GetId(),
+				instance.GetAppId(),
+				instance.GetServiceId(),
+				instance.GetType(),
+			}...,
+		)
+	}
+
+	for appID, cost := range apps {
+		ch <- constMetric(
+			c.AppCost,
+			cost*catalog.HoursPerMonth,
+			[]string{
+				appID,
+			}...,
+		)
+	}
+}
+
+// running is a function that determines whether an Instance in the status is running (and is being charged)
+func running(status koyeb.InstanceStatus) bool {
+	switch status {
+	case koyeb.INSTANCESTATUS_ALLOCATING,
+		koyeb.INSTANCESTATUS_STARTING,
+		koyeb.INSTANCESTATUS_HEALTHY,
+		koyeb.INSTANCESTATUS_UNHEALTHY,
+		koyeb.INSTANCESTATUS_STOPPING:
+		return true
+	default:
+		return false
+	}
+}
+
+// update is a method that reconciles the Instances against the slots retained from the previous scrape
+// A replica slot (regional deployment and replica index) is occupied by its most recently created Instance
+// When a slot's Instance changes, the previous Instance was replaced
+// Returns the Service ID of each replaced Instance keyed by the Instance ID
+// Callers must hold the mutex
+func (c *InstancesCollector) update(instances []koyeb.InstanceListItem, now time.Time) map[string]string {
+	// Determine the most recently created Instance in each slot
+	latest := map[string]koyeb.InstanceListItem{}
+	for _, instance := range instances {
+		slot := fmt.Sprintf("%s/%d", instance.GetRegionalDeploymentId(), instance.GetReplicaIndex())
+		if prev, ok := latest[slot]; ok && !instance.GetCreatedAt().After(prev.GetCreatedAt()) {
+			continue
+		}
+		latest[slot] = instance
+	}
+
+	// Service ID of each replaced Instance keyed by the Instance ID
+	replaced := map[string]string{}
+	for slot, instance := range latest {
+		serviceID := instance.GetServiceId()
+		if _, ok := c.replacements[serviceID]; !ok {
+			c.replacements[serviceID] = newCounter(now)
+		}
+
+		prev, ok := c.slots[slot]
+		if ok && prev != instance.GetId() {
+			c.replacements[serviceID].inc(prometheus.Labels{"instance_id": prev}, now)
+			c.history[serviceID] = append(c.history[serviceID], now)
+			replaced[prev] = serviceID
+		}
+
+		c.slots[slot] = instance.GetId()
+	}
+
+	// Forget slots that are no longer listed
+	for slot := range c.slots {
+		if _, ok := latest[slot]; !ok {
+			delete(c.slots, slot)
+		}
+	}
+
+	// Forget replacements that have fallen outside the window
+	for serviceID, times := range c.history {
+		i := 0
+		for i < len(times) && now.Sub(times[i]) > c.window {
+			i++
+		}
+		if i == len(times) {
+			delete(c.history, serviceID)
+			continue
+		}
+		c.history[serviceID] = times[i:]
+	}
+
+	return replaced
+}
+
+// terminate is a method that counts termination reasons of replaced Instances using Instance events
+// The reason is the most recent event's "reason" metadata if present, otherwise its type
+// Callers must hold the mutex
+func (c *InstancesCollector) terminate(replaced map[string]string, now time.Time) {
+	logger := c.logger.With("method", "terminate")
+
+	ids := make([]string, 0, len(replaced))
+	for id := range replaced {
+		ids = append(ids, id)
+	}
+
+	rqst := c.client.InstancesApi.ListInstanceEvents(c.ctx).InstanceIds(ids).Order("desc")
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		logger.Error("unable to list Instance events", "err", err)
+		return
+	}
+
+	// Events are ordered most recent first so the first event for each Instance is used
+	seen := map[string]bool{}
+	for _, event := range resp.Events {
+		instanceID := event.GetInstanceId()
+		if seen[instanceID] {
+			continue
+		}
+		serviceID, ok := replaced[instanceID]
+		if !ok {
+			continue
+		}
+		seen[instanceID] = true
+
+		reason := event.GetType()
+		if value, ok := event.Metadata["reason"].(string); ok && value != "" {
+			reason = value
+		}
+
+		if _, ok := c.terminations[serviceID]; !ok {
+			c.terminations[serviceID] = map[string]*counter{}
+		}
+		if _, ok := c.terminations[serviceID][reason]; !ok {
+			c.terminations[serviceID][reason] = newCounter(now)
+		}
+		c.terminations[serviceID][reason].inc(prometheus.Labels{"event_id": event.GetId()}, event.GetWhen())
+	}
 }
@@ -0,0 +1,334 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/catalog"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newTestInstance is a function that returns an Instance of the Service in the replica slot
+func newTestInstance(id, serviceID, regionalDeploymentID string, replicaIndex int64, createdAt time.Time) koyeb.InstanceListItem {
+	instance := koyeb.InstanceListItem{}
+	instance.SetId(id)
+	instance.SetServiceId(serviceID)
+	instance.SetRegionalDeploymentId(regionalDeploymentID)
+	instance.SetReplicaIndex(replicaIndex)
+	instance.SetCreatedAt(createdAt)
+	return instance
+}
+
+func TestInstancesCollectorUpdate(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	window := 15 * time.Minute
+
+	type scrape struct {
+		at        time.Time
+		instances []koyeb.InstanceListItem
+		// Replaced Instance IDs keyed by Service ID
+		replaced map[string]string
+	}
+	tests := []struct {
+		name      string
+		threshold int
+		scrapes   []scrape
+		// Expected state after the final scrape
+		replacements float64
+		history      int
+		crashLoop    bool
+	}{
+		{
+			name:      "first sighting",
+			threshold: 1,
+			scrapes: []scrape{
+				{
+					at: start,
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i1", "s1", "rd1", 0, start),
+						newTestInstance("i2", "s1", "rd1", 1, start),
+					},
+					replaced: map[string]string{},
+				},
+			},
+		},
+		{
+			name:      "replacement",
+			threshold: 1,
+			scrapes: []scrape{
+				{
+					at: start,
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i1", "s1", "rd1", 0, start),
+					},
+					replaced: map[string]string{},
+				},
+				{
+					at: start.Add(time.Minute),
+					instances: []koyeb.InstanceListItem{
+						// The replaced Instance may still be listed while it is stopping
+						newTestInstance("i1", "s1", "rd1", 0, start),
+						newTestInstance("i2", "s1", "rd1", 0, start.Add(30*time.Second)),
+					},
+					replaced: map[string]string{"i1": "s1"},
+				},
+			},
+			replacements: 1,
+			history:      1,
+		},
+		{
+			name:      "threshold crossing",
+			threshold: 1,
+			scrapes: []scrape{
+				{
+					at: start,
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i1", "s1", "rd1", 0, start),
+					},
+					replaced: map[string]string{},
+				},
+				{
+					at: start.Add(time.Minute),
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i2", "s1", "rd1", 0, start.Add(time.Minute)),
+					},
+					replaced: map[string]string{"i1": "s1"},
+				},
+				{
+					at: start.Add(2 * time.Minute),
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i3", "s1", "rd1", 0, start.Add(2*time.Minute)),
+					},
+					replaced: map[string]string{"i2": "s1"},
+				},
+			},
+			replacements: 2,
+			history:      2,
+			crashLoop:    true,
+		},
+		{
+			name:      "window edge",
+			threshold: 1,
+			scrapes: []scrape{
+				{
+					at: start,
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i1", "s1", "rd1", 0, start),
+					},
+					replaced: map[string]string{},
+				},
+				{
+					at: start.Add(time.Minute),
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i2", "s1", "rd1", 0, start.Add(time.Minute)),
+					},
+					replaced: map[string]string{"i1": "s1"},
+				},
+				{
+					// The first replacement is exactly at the edge of the window and is retained
+					at: start.Add(time.Minute + window),
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i3", "s1", "rd1", 0, start.Add(window)),
+					},
+					replaced: map[string]string{"i2": "s1"},
+				},
+				{
+					// The first replacement has fallen outside the window and is pruned
+					at: start.Add(time.Minute + window + time.Second),
+					instances: []koyeb.InstanceListItem{
+						newTestInstance("i3", "s1", "rd1", 0, start.Add(window)),
+					},
+					replaced: map[string]string{},
+				},
+			},
+			replacements: 2,
+			history:      1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewInstancesCollector(context.Background(), nil, nil, newTestLogger(), nil, test.threshold, window)
+
+			for i, s := range test.scrapes {
+				replaced := c.update(s.instances, s.at)
+				if len(replaced) != len(s.replaced) {
+					t.Errorf("scrape %d: got %d replaced Instances, want %d", i, len(replaced), len(s.replaced))
+				}
+				for instanceID, serviceID := range s.replaced {
+					if got := replaced[instanceID]; got != serviceID {
+						t.Errorf("scrape %d: got Service %q for replaced Instance %q, want %q", i, got, instanceID, serviceID)
+					}
+				}
+			}
+
+			if got := c.replacements["s1"].value; got != test.replacements {
+				t.Errorf("got %v replacements, want %v", got, test.replacements)
+			}
+			if got := len(c.history["s1"]); got != test.history {
+				t.Errorf("got %d replacements within the window, want %d", got, test.history)
+			}
+			if got := len(c.history["s1"]) > c.threshold; got != test.crashLoop {
+				t.Errorf("got crash-loop %t, want %t", got, test.crashLoop)
+			}
+		})
+	}
+}
+
+func TestInstancesCollectorUpdateForgetsSlots(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := NewInstancesCollector(context.Background(), nil, nil, newTestLogger(), nil, 1, time.Minute)
+
+	c.update([]koyeb.InstanceListItem{
+		newTestInstance("i1", "s1", "rd1", 0, start),
+	}, start)
+	c.update([]koyeb.InstanceListItem{}, start.Add(time.Minute))
+
+	if len(c.slots) != 0 {
+		t.Errorf("got %d slots, want 0", len(c.slots))
+	}
+
+	// An Instance in a slot that is no longer tracked is a first sighting and not a replacement
+	replaced := c.update([]koyeb.InstanceListItem{
+		newTestInstance("i2", "s1", "rd1", 0, start.Add(2*time.Minute)),
+	}, start.Add(2*time.Minute))
+	if len(replaced) != 0 {
+		t.Errorf("got %d replaced Instances, want 0", len(replaced))
+	}
+}
+
+func TestInstancesCollectorTerminate(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newEvent := func(id, instanceID, eventType string, metadata map[string]any) koyeb.InstanceEvent {
+		event := koyeb.InstanceEvent{}
+		event.SetId(id)
+		event.SetInstanceId(instanceID)
+		event.SetType(eventType)
+		event.SetWhen(when)
+		event.SetMetadata(metadata)
+		return event
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/instance_events" {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Events are ordered most recent first
+		reply := koyeb.ListInstanceEventsReply{}
+		reply.SetEvents([]koyeb.InstanceEvent{
+			newEvent("e1", "i1", "instance.terminated", map[string]any{"reason": "OOMKilled"}),
+			newEvent("e2", "i1", "instance.unhealthy", map[string]any{}),
+			newEvent("e3", "i2", "instance.unhealthy", map[string]any{}),
+			newEvent("e4", "i3", "instance.terminated", map[string]any{"reason": ""}),
+			newEvent("e5", "i4", "instance.terminated", map[string]any{"reason": "Error"}),
+		})
+		writeJSON(t, w, reply)
+	}))
+
+	c := NewInstancesCollector(context.Background(), client, nil, newTestLogger(), nil, 1, time.Minute)
+
+	// i4 was not replaced and its events are ignored
+	c.terminate(map[string]string{
+		"i1": "s1",
+		"i2": "s1",
+		"i3": "s2",
+	}, when)
+
+	want := map[string]map[string]float64{
+		"s1": {
+			// The most recent event's reason
+			"OOMKilled": 1,
+			// The event's type when it has no reason
+			"instance.unhealthy": 1,
+		},
+		"s2": {
+			// The event's type when its reason is empty
+			"instance.terminated": 1,
+		},
+	}
+	if len(c.terminations) != len(want) {
+		t.Errorf("got terminations for %d Services, want %d", len(c.terminations), len(want))
+	}
+	for serviceID, reasons := range want {
+		if len(c.terminations[serviceID]) != len(reasons) {
+			t.Errorf("Service %q: got %d reasons, want %d", serviceID, len(c.terminations[serviceID]), len(reasons))
+		}
+		for reason, value := range reasons {
+			got, ok := c.terminations[serviceID][reason]
+			if !ok {
+				t.Errorf("Service %q: missing reason %q", serviceID, reason)
+				continue
+			}
+			if got.value != value {
+				t.Errorf("Service %q reason %q: got %v, want %v", serviceID, reason, got.value, value)
+			}
+		}
+	}
+}
+
+func TestInstancesCollectorCollectPages(t *testing.T) {
+	// More Instances than fit on a page
+	count := pageSize + 1
+	created := time.Now().Add(-time.Hour)
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/instances" {
+			http.NotFound(w, r)
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit == 0 {
+			t.Error("Instances listed without a limit")
+			limit = count
+		}
+
+		instances := []koyeb.InstanceListItem{}
+		for i := offset; i < min(offset+limit, count); i++ {
+			instances = append(instances, newTestInstance(fmt.Sprintf("i%d", i), "s1", fmt.Sprintf("rd%d", i), 0, created))
+		}
+
+		reply := koyeb.ListInstancesReply{}
+		reply.SetInstances(instances)
+		reply.SetOffset(int64(offset))
+		reply.SetCount(int64(count))
+		writeJSON(t, w, reply)
+	}))
+
+	logger := newTestLogger()
+	instanceTypes, err := catalog.New(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewInstancesCollector(context.Background(), client, newTestStatus(t), logger, instanceTypes, 3, time.Hour)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every Instance on every page is collected and occupies a replica slot
+	got := 0
+	for _, family := range families {
+		if family.GetName() == instanceUp.FQName() {
+			got = len(family.GetMetric())
+		}
+	}
+	if got != count {
+		t.Errorf("got %d Instances, want %d", got, count)
+	}
+	if got := len(c.slots); got != count {
+		t.Errorf("got %d replica slots, want %d", got, count)
+	}
+}
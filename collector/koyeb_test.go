@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+// newTestClient is a function that returns a Koyeb API client whose requests are served by handler
+func newTestClient(t *testing.T, handler http.Handler) *koyeb.APIClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := koyeb.NewConfiguration()
+	cfg.Servers = koyeb.ServerConfigurations{
+		{
+			URL: server.URL,
+		},
+	}
+	cfg.HTTPClient = server.Client()
+
+	return koyeb.NewAPIClient(cfg)
+}
+
+// newTestStatus is a function that returns a probe status channel whose statuses are discarded
+func newTestStatus(t *testing.T) chan probe.Status {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ch := make(chan probe.Status)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+			}
+		}
+	}()
+
+	return ch
+}
+
+// newTestLogger is a function that returns a logger whose records are discarded
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// writeJSON is a function that writes v as the JSON body of the response
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,318 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// logsPageSize is the number of log lines requested per page
+const logsPageSize int = 100
+
+// Ensure that LogsCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*LogsCollector)(nil)
+
+// LogRule is a named regular expression against which log lines are matched
+type LogRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// ParseLogRule is a function that parses a log rule represented as {name}={regex}
+func ParseLogRule(s string) (LogRule, error) {
+	name, expr, ok := strings.Cut(s, "=")
+	if !ok || name == "" || expr == "" {
+		return LogRule{}, fmt.Errorf("invalid log rule: %q", s)
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return LogRule{}, fmt.Errorf("invalid log rule (%s): %w", name, err)
+	}
+
+	return LogRule{
+		Name:    name,
+		Pattern: pattern,
+	}, nil
+}
+
+// logsCursor is the position from which a Service's log lines are next read
+// The Koyeb API's start time is precise to the second so at is a whole second
+// Lines are read from at (inclusive) and the first read lines in the second at are skipped because they have been read
+// Lines in the same second are assumed to be returned in the same order
+type logsCursor struct {
+	at   time.Time
+	read int
+}
+
+// logsKey is the set of label values by which matching log lines are counted
+type logsKey struct {
+	serviceID string
+	stream    string
+	rule      string
+}
+
+// LogsCollector counts the runtime log lines of Services that match rules
+// Log lines are read when metrics are collected; at most budget lines are read per collection
+type LogsCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	rules  []LogRule
+	budget int
+
+	// State retained between scrapes
+	// cursors is the position of the most recent log line read keyed by Service ID
+	// start is the index of the Service that is read first so that Services skipped when the budget is exhausted are read first by the next collection
+	// counts is the number of matching log lines
+	// exhausted is the number of collections that exhausted the budget
+	mu        sync.Mutex
+	cursors   map[string]logsCursor
+	start     int
+	counts    map[logsKey]*counter
+	exhausted *counter
+
+	Lines           *prometheus.Desc
+	BudgetExhausted *prometheus.Desc
+}
+
+// NewLogsCollector is a function that creates a new LogsCollector
+func NewLogsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, rules []LogRule, budget int) *LogsCollector {
+	subsystem := "log"
+	logger := l.With("collector", subsystem)
+
+	return &LogsCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		rules:  rules,
+		budget: budget,
+
+		cursors:   map[string]logsCursor{},
+		counts:    map[logsKey]*counter{},
+		exhausted: newCounter(time.Now()),
+
+		Lines:           logLinesTotal.Desc(),
+		BudgetExhausted: logBudgetExhaustedTotal.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *LogsCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	services, err := listServices(c.ctx, c.client)
+	if err != nil {
+		msg := "unable to list Services"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	remaining := c.budget
+	exhausted := false
+
+	// The index of the first Service that is not (fully) read because the budget is exhausted
+	rotate := -1
+
+	current := map[string]logsCursor{}
+	for i := range services {
+		index := (c.start + i) % len(services)
+		id := services[index].GetId()
+
+		// The first collection for a Service starts reading from now so that historical log lines are not counted
+		cursor, ok := c.cursors[id]
+		if !ok {
+			current[id] = logsCursor{at: now.Truncate(time.Second)}
+			continue
+		}
+
+		if remaining <= 0 {
+			if rotate < 0 {
+				rotate = index
+			}
+			exhausted = true
+			current[id] = logsCursor{at: now.Truncate(time.Second)}
+			continue
+		}
+
+		read, next, more, err := c.read(id, cursor, now, remaining)
+
+		// Lines read before an error are counted and are not read again
+		remaining -= read
+		current[id] = next
+
+		if err != nil {
+			logger.Info("unable to query logs",
+				"service_id", id,
+				"err", err,
+			)
+			continue
+		}
+
+		if more {
+			if rotate < 0 {
+				rotate = (index + 1) % len(services)
+			}
+			exhausted = true
+		}
+	}
+
+	if exhausted {
+		logger.Info("log line budget exhausted", "budget", c.budget)
+		c.exhausted.inc(nil, now)
+	}
+
+	// Services that are no longer listed are forgotten
+	c.cursors = current
+	if rotate >= 0 {
+		c.start = rotate
+	}
+
+	for key, value := range c.counts {
+		ch <- value.metric(
+			c.Lines,
+			[]string{
+				key.serviceID,
+				key.stream,
+				key.rule,
+			}...,
+		)
+	}
+	ch <- c.exhausted.metric(c.BudgetExhausted)
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *LogsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Lines
+	ch <- c.BudgetExhausted
+}
+
+// read is a method that matches the Service's runtime log lines from the cursor until end against the rules
+// At most budget lines are read; if the budget is exhausted, the remaining lines are skipped
+// Returns the number of lines read, the cursor from which the next read starts and whether lines were skipped
+// If an error occurs, the returned cursor is that of the most recent line read
+func (c *LogsCollector) read(serviceID string, cursor logsCursor, end time.Time, budget int) (int, logsCursor, bool, error) {
+	read := 0
+	for {
+		if read >= budget {
+			// Lines that were not read because the budget was exhausted are skipped
+			return read, logsCursor{at: end.Truncate(time.Second)}, true, nil
+		}
+
+		// Lines at the cursor's time that have been read are requested again (and skipped)
+		limit := cursor.read + min(logsPageSize, budget-read)
+
+		rqst := c.client.LogsApi.QueryLogs(c.ctx).
+			Type_("runtime").
+			ServiceId(serviceID).
+			Start(cursor.at).
+			End(end).
+			Order("asc").
+			Limit(strconv.Itoa(limit))
+		resp, _, err := rqst.Execute()
+		if err != nil {
+			// Resume from the most recent line read
+			return read, cursor, false, err
+		}
+
+		before := read
+		skip := cursor.read
+		for _, entry := range resp.Data {
+			second := entry.GetCreatedAt().Truncate(time.Second)
+			if skip > 0 && !second.After(cursor.at) {
+				skip--
+				continue
+			}
+
+			read++
+			c.match(serviceID, entry)
+
+			// Resume at the second of the most recent line read skipping the lines in that second that have been read
+			if second.After(cursor.at) {
+				cursor = logsCursor{at: second}
+			}
+			cursor.read++
+		}
+
+		pagination := resp.GetPagination()
+		if !pagination.GetHasMore() || len(resp.Data) == 0 {
+			return read, cursor, false, nil
+		}
+
+		// If the API returns fewer lines than were requested and every line has been read, the cursor cannot advance
+		// The remaining lines in the cursor's second are skipped
+		if read == before {
+			next := end.Truncate(time.Second)
+			if !next.After(cursor.at) {
+				next = cursor.at.Add(time.Second)
+			}
+			return read, logsCursor{at: next}, true, nil
+		}
+	}
+}
+
+// match is a method that counts the rules that the log line matches
+// The Instance that wrote the log line (if labeled) is recorded as an exemplar
+func (c *LogsCollector) match(serviceID string, entry koyeb.LogEntry) {
+	stream := ""
+	if s, ok := entry.Labels["stream"].(string); ok {
+		stream = s
+	}
+
+	var exemplar prometheus.Labels
+	if id, ok := entry.Labels["instance_id"].(string); ok && id != "" {
+		exemplar = prometheus.Labels{
+			"instance_id": id,
+		}
+	}
+
+	msg := entry.GetMsg()
+	for _, rule := range c.rules {
+		if !rule.Pattern.MatchString(msg) {
+			continue
+		}
+
+		key := logsKey{
+			serviceID: serviceID,
+			stream:    stream,
+			rule:      rule.Name,
+		}
+		if _, ok := c.counts[key]; !ok {
+			c.counts[key] = newCounter(time.Now())
+		}
+		c.counts[key].inc(exemplar, entry.GetCreatedAt())
+	}
+}
@@ -0,0 +1,338 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestParseLogRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		name    string
+		wantErr bool
+	}{
+		{rule: "error=level=error", name: "error"},
+		{rule: `panic=^panic:`, name: "panic"},
+		{rule: "error", wantErr: true},
+		{rule: "=level=error", wantErr: true},
+		{rule: "error=", wantErr: true},
+		{rule: "error=(", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			rule, err := ParseLogRule(test.rule)
+			if test.wantErr {
+				if err == nil {
+					t.Error("got nil error, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rule.Name != test.name {
+				t.Errorf("got name %q, want %q", rule.Name, test.name)
+			}
+		})
+	}
+}
+
+func TestLogsCollectorMatch(t *testing.T) {
+	rules := []LogRule{}
+	for _, s := range []string{"error=level=error", "panic=^panic:"} {
+		rule, err := ParseLogRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+
+	c := &LogsCollector{
+		rules:  rules,
+		counts: map[logsKey]*counter{},
+	}
+
+	for _, line := range []struct {
+		msg    string
+		stream string
+	}{
+		{msg: "level=info msg=started", stream: "stdout"},
+		{msg: "level=error msg=failed", stream: "stdout"},
+		{msg: "level=error msg=failed", stream: "stderr"},
+		{msg: "panic: runtime error", stream: "stderr"},
+	} {
+		msg := line.msg
+		c.match("service", koyeb.LogEntry{
+			Msg: &msg,
+			Labels: map[string]interface{}{
+				"stream": line.stream,
+			},
+		})
+	}
+
+	want := map[logsKey]float64{
+		{serviceID: "service", stream: "stdout", rule: "error"}: 1,
+		{serviceID: "service", stream: "stderr", rule: "error"}: 1,
+		{serviceID: "service", stream: "stderr", rule: "panic"}: 1,
+	}
+	if len(c.counts) != len(want) {
+		t.Fatalf("got %d counts, want %d: %v", len(c.counts), len(want), c.counts)
+	}
+	for key, value := range want {
+		got, ok := c.counts[key]
+		if !ok {
+			t.Errorf("%+v: missing", key)
+			continue
+		}
+		if got.value != value {
+			t.Errorf("%+v: got %v, want %v", key, got.value, value)
+		}
+	}
+}
+
+// testLogs is a fake of the Koyeb API's runtime log lines of Services ordered by time
+// Lines from the request's start (which is precise to the second) are returned by page
+type testLogs struct {
+	t       *testing.T
+	mu      sync.Mutex
+	lines   map[string][]koyeb.LogEntry
+	queried []string
+	// fail is the number of the request (counting from 1) that fails
+	fail int
+	// limit caps the number of lines returned per request (if set)
+	limit int
+}
+
+// ServeHTTP implements http.Handler
+func (l *testLogs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/streams/logs/query" {
+		http.NotFound(w, r)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	serviceID := r.URL.Query().Get("service_id")
+	l.queried = append(l.queried, serviceID)
+	if len(l.queried) == l.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339, r.URL.Query().Get("start"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if l.limit > 0 {
+		limit = min(limit, l.limit)
+	}
+
+	entries := []koyeb.LogEntry{}
+	for _, entry := range l.lines[serviceID] {
+		if !entry.GetCreatedAt().Before(start) {
+			entries = append(entries, entry)
+		}
+	}
+
+	pagination := koyeb.QueryLogsReplyPagination{}
+	pagination.SetHasMore(len(entries) > limit)
+
+	reply := koyeb.QueryLogsReply{}
+	reply.SetData(entries[:min(limit, len(entries))])
+	reply.SetPagination(pagination)
+	writeJSON(l.t, w, reply)
+}
+
+// newTestLogEntries is a function that returns count error log lines written a millisecond apart from start
+func newTestLogEntries(start time.Time, count int) []koyeb.LogEntry {
+	entries := make([]koyeb.LogEntry, 0, count)
+	for i := range count {
+		entry := koyeb.LogEntry{}
+		entry.SetCreatedAt(start.Add(time.Duration(i) * time.Millisecond))
+		entry.SetMsg(fmt.Sprintf("level=error line=%d", i))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// newTestLogs is a function that returns a LogsCollector that lists the Services and reads their log lines from logs
+func newTestLogs(t *testing.T, logs *testLogs, budget int, serviceIDs ...string) *LogsCollector {
+	t.Helper()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/services" {
+			logs.ServeHTTP(w, r)
+			return
+		}
+
+		services := []koyeb.ServiceListItem{}
+		for _, id := range serviceIDs {
+			service := koyeb.ServiceListItem{}
+			service.SetId(id)
+			services = append(services, service)
+		}
+
+		reply := koyeb.ListServicesReply{}
+		reply.SetServices(services)
+		writeJSON(t, w, reply)
+	}))
+
+	rule, err := ParseLogRule("error=level=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewLogsCollector(context.Background(), client, newTestStatus(t), newTestLogger(), []LogRule{rule}, budget)
+}
+
+func TestLogsCollectorResume(t *testing.T) {
+	// More lines in the same second than fit on a page followed by lines in the next second
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	lines := append(newTestLogEntries(start, logsPageSize+50), newTestLogEntries(start.Add(time.Second), 10)...)
+
+	logs := &testLogs{
+		t: t,
+		lines: map[string][]koyeb.LogEntry{
+			"s1": lines,
+		},
+		// The request for the second page fails
+		fail: 2,
+	}
+	c := newTestLogs(t, logs, 1000, "s1")
+
+	// Read from start rather than priming the Service's cursor
+	c.cursors["s1"] = logsCursor{at: start}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	key := logsKey{
+		serviceID: "s1",
+		rule:      "error",
+	}
+
+	scrapes := []struct {
+		name string
+		want float64
+	}{
+		{
+			// The lines read before the error are counted
+			name: "error",
+			want: float64(logsPageSize),
+		},
+		{
+			// The next scrape resumes after the most recent line read (in the same second)
+			name: "resume",
+			want: float64(len(lines)),
+		},
+		{
+			// Lines are not counted again
+			name: "again",
+			want: float64(len(lines)),
+		},
+	}
+	for _, scrape := range scrapes {
+		if _, err := registry.Gather(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := 0.0
+		if value, ok := c.counts[key]; ok {
+			got = value.value
+		}
+		if got != scrape.want {
+			t.Errorf("%s: got %v lines, want %v", scrape.name, got, scrape.want)
+		}
+	}
+}
+
+func TestLogsCollectorCappedLimit(t *testing.T) {
+	// More lines in the same second than the API returns per request
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logs := &testLogs{
+		t: t,
+		lines: map[string][]koyeb.LogEntry{
+			"s1": newTestLogEntries(start, 120),
+		},
+		limit: 50,
+	}
+	c := newTestLogs(t, logs, 1000, "s1")
+	c.cursors["s1"] = logsCursor{at: start}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	done := make(chan error)
+	go func() {
+		_, err := registry.Gather()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("collection did not complete")
+	}
+
+	// The lines of the first page are counted and the remaining lines in the second are skipped
+	if got := c.counts[logsKey{serviceID: "s1", rule: "error"}].value; got != 50 {
+		t.Errorf("got %v lines, want 50", got)
+	}
+	if got := c.exhausted.value; got != 1 {
+		t.Errorf("got %v exhausted collections, want 1", got)
+	}
+	if got := c.cursors["s1"].at; !got.After(start) {
+		t.Errorf("got cursor %s, want after %s", got, start)
+	}
+}
+
+func TestLogsCollectorRotates(t *testing.T) {
+	// Every Service has more lines than the budget
+	future := time.Now().Add(time.Hour)
+	logs := &testLogs{
+		t: t,
+		lines: map[string][]koyeb.LogEntry{
+			"s0": newTestLogEntries(future, 2),
+			"s1": newTestLogEntries(future, 2),
+			"s2": newTestLogEntries(future, 2),
+		},
+	}
+	c := newTestLogs(t, logs, 1, "s0", "s1", "s2")
+	for _, id := range []string{"s0", "s1", "s2"} {
+		c.cursors[id] = logsCursor{at: time.Now().Truncate(time.Second)}
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	// The Services skipped because the budget is exhausted are read first by the next scrape
+	for _, want := range []string{"s0", "s1", "s2", "s0"} {
+		logs.mu.Lock()
+		logs.queried = nil
+		logs.mu.Unlock()
+
+		if _, err := registry.Gather(); err != nil {
+			t.Fatal(err)
+		}
+
+		logs.mu.Lock()
+		queried := logs.queried
+		logs.mu.Unlock()
+		if len(queried) == 0 || queried[0] != want {
+			t.Errorf("got queried %v, want %s first", queried, want)
+		}
+	}
+}
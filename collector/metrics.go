@@ -0,0 +1,1161 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricType is the type of a metric
+type metricType string
+
+const (
+	gaugeMetric     metricType = "Gauge"
+	counterMetric   metricType = "Counter"
+	histogramMetric metricType = "Histogram"
+)
+
+// change is a change to the name or type of a metric that breaks dashboards and alerts that use it
+// Previous is the metric's previous name (without the namespace)
+type change struct {
+	Previous    string
+	Description string
+}
+
+// metric is the definition of a metric exported by a collector
+// Collectors' Descs, their help text and the README's tables of metrics and changed metrics are generated from these definitions
+type metric struct {
+	Subsystem string
+	Name      string
+	Type      metricType
+	Help      string
+	Labels    []string
+	Changes   []change
+}
+
+// FQName is a method that returns the metric's fully-qualified name
+func (m metric) FQName() string {
+	return prometheus.BuildFQName(namespace, m.Subsystem, m.Name)
+}
+
+// Desc is a method that returns the metric's Desc
+// The Desc is created once so that the metric's type can be found by its Desc (see constMetric)
+func (m metric) Desc() *prometheus.Desc {
+	descsMu.Lock()
+	defer descsMu.Unlock()
+
+	name := m.FQName()
+	if desc, ok := descs[name]; ok {
+		return desc
+	}
+
+	desc := prometheus.NewDesc(
+		name,
+		m.Help,
+		m.Labels,
+		nil,
+	)
+	descs[name] = desc
+	metricTypes[desc] = m.Type
+	return desc
+}
+
+var (
+	// descs are the metrics' Descs keyed by their fully-qualified names
+	// metricTypes are the metrics' types keyed by their Descs
+	descsMu     sync.Mutex
+	descs       = map[string]*prometheus.Desc{}
+	metricTypes = map[*prometheus.Desc]metricType{}
+)
+
+// valueType is a function that returns the value type of the metric described by desc as defined by its metric definition
+// Panics if desc is not the Desc of a metric definition
+func valueType(desc *prometheus.Desc) prometheus.ValueType {
+	descsMu.Lock()
+	t, ok := metricTypes[desc]
+	descsMu.Unlock()
+
+	switch {
+	case !ok:
+		panic(fmt.Sprintf("metric is not defined: %s", desc))
+	case t == counterMetric:
+		return prometheus.CounterValue
+	case t == gaugeMetric:
+		return prometheus.GaugeValue
+	default:
+		panic(fmt.Sprintf("metric is not a constant metric: %s", desc))
+	}
+}
+
+// constMetric is a function that returns a constant metric whose value type is that of its metric definition
+// Collectors use constMetric rather than prometheus.MustNewConstMetric so that the type of the metrics that they emit is the type that is documented
+func constMetric(desc *prometheus.Desc, value float64, labelValues ...string) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, valueType(desc), value, labelValues...)
+}
+
+// HistogramOpts is a method that returns the options of a histogram for the metric with the given buckets
+func (m metric) HistogramOpts(buckets []float64) prometheus.HistogramOpts {
+	return prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: m.Subsystem,
+		Name:      m.Name,
+		Help:      m.Help,
+		Buckets:   buckets,
+	}
+}
+
+var (
+	// Apps
+	appUp = metric{
+		Subsystem: "app",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the App is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"name",
+			"organization",
+			"status",
+		},
+		Changes: []change{
+			{
+				Previous:    "apps_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	// Billing
+	organizationPlanInfo = metric{
+		Subsystem: "organization",
+		Name:      "plan_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Organization's plan",
+		Labels: []string{
+			"organization_id",
+			"plan",
+		},
+	}
+	billingSubscriptionInfo = metric{
+		Subsystem: "billing",
+		Name:      "subscription_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Organization's subscription and its status",
+		Labels: []string{
+			"organization_id",
+			"id",
+			"status",
+		},
+	}
+	billingNextInvoiceAmount = metric{
+		Subsystem: "billing",
+		Name:      "next_invoice_amount",
+		Type:      gaugeMetric,
+		Help:      "Amount (excluding tax) of the Organization's next (current) invoice",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingAmountPayable = metric{
+		Subsystem: "billing",
+		Name:      "amount_payable",
+		Type:      gaugeMetric,
+		Help:      "Amount payable for the Organization's subscription",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingAmountPaid = metric{
+		Subsystem: "billing",
+		Name:      "amount_paid",
+		Type:      gaugeMetric,
+		Help:      "Amount paid for the Organization's subscription",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingAmountRemaining = metric{
+		Subsystem: "billing",
+		Name:      "amount_remaining",
+		Type:      gaugeMetric,
+		Help:      "Amount outstanding for the Organization's subscription",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingUnpaidInvoices = metric{
+		Subsystem: "billing",
+		Name:      "unpaid_invoices",
+		Type:      gaugeMetric,
+		Help:      "1 if the Organization has unpaid invoices, 0 otherwise",
+		Labels: []string{
+			"organization_id",
+		},
+	}
+	billingPaymentFailed = metric{
+		Subsystem: "billing",
+		Name:      "payment_failed",
+		Type:      gaugeMetric,
+		Help:      "1 if the most recent payment for the Organization's subscription failed, 0 otherwise",
+		Labels: []string{
+			"organization_id",
+			"error_code",
+		},
+	}
+	billingTrialing = metric{
+		Subsystem: "billing",
+		Name:      "trialing",
+		Type:      gaugeMetric,
+		Help:      "1 if the Organization is trialing, 0 otherwise",
+		Labels: []string{
+			"organization_id",
+		},
+	}
+	billingTrialEndTimestampSeconds = metric{
+		Subsystem: "billing",
+		Name:      "trial_end_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time the Organization's trial ends in Unix epoch seconds",
+		Labels: []string{
+			"organization_id",
+		},
+	}
+	billingTrialMaxSpend = metric{
+		Subsystem: "billing",
+		Name:      "trial_max_spend",
+		Type:      gaugeMetric,
+		Help:      "Maximum amount that may be spent during the Organization's trial",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingCurrentSpend = metric{
+		Subsystem: "billing",
+		Name:      "current_spend",
+		Type:      gaugeMetric,
+		Help:      "Amount spent by the Organization during the current subscription period",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	billingTrialCreditBalance = metric{
+		Subsystem: "billing",
+		Name:      "trial_credit_balance",
+		Type:      gaugeMetric,
+		Help:      "Amount of the Organization's trial credit that remains to be spent",
+		Labels: []string{
+			"organization_id",
+			"currency",
+		},
+	}
+	// Budget
+	budgetLimit = metric{
+		Subsystem: "budget",
+		Name:      "limit",
+		Type:      gaugeMetric,
+		Help:      "Monthly budget of the Organization or App",
+		Labels: []string{
+			"scope",
+			"id",
+			"name",
+		},
+	}
+	budgetSpent = metric{
+		Subsystem: "budget",
+		Name:      "spent",
+		Type:      gaugeMetric,
+		Help:      "Amount spent by the Organization or App during the current month",
+		Labels: []string{
+			"scope",
+			"id",
+			"name",
+		},
+	}
+	budgetProjected = metric{
+		Subsystem: "budget",
+		Name:      "projected",
+		Type:      gaugeMetric,
+		Help:      "Linear projection of the amount that will be spent by the Organization or App by the end of the current month",
+		Labels: []string{
+			"scope",
+			"id",
+			"name",
+		},
+	}
+	// Builds
+	buildFailuresTotal = metric{
+		Subsystem: "build",
+		Name:      "failures_total",
+		Type:      counterMetric,
+		Help:      "Total number of the Service's failed builds by the reason for which they failed",
+		Labels: []string{
+			"service_id",
+			"reason",
+		},
+	}
+	// Credentials
+	credentialUp = metric{
+		Subsystem: "credential",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Credential is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"organization_id",
+			"user_id",
+			"name",
+		},
+		Changes: []change{
+			{
+				Previous:    "credentials_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	credentialInfo = metric{
+		Subsystem: "credential",
+		Name:      "info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Credential's type (user or organization) and the user that created it",
+		Labels: []string{
+			"id",
+			"name",
+			"type",
+			"user_id",
+		},
+	}
+	credentialByUser = metric{
+		Subsystem: "credential",
+		Name:      "by_user",
+		Type:      gaugeMetric,
+		Help:      "Number of Credentials by the user that created them",
+		Labels: []string{
+			"user_id",
+		},
+	}
+	credentialIsExporterToken = metric{
+		Subsystem: "credential",
+		Name:      "is_exporter_token",
+		Type:      gaugeMetric,
+		Help:      "1 if the Credential is the one whose API token the exporter uses (set by --exporter_credential), 0 otherwise",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	credentialCreatedTimestampSeconds = metric{
+		Subsystem: "credential",
+		Name:      "created_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time the Credential was created in Unix epoch seconds",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	credentialLastUpdatedTimestampSeconds = metric{
+		Subsystem: "credential",
+		Name:      "last_updated_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time the Credential was last updated in Unix epoch seconds",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	// Deployments
+	deploymentUp = metric{
+		Subsystem: "deployment",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Deployment is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"app_id",
+			"deployment_group",
+			"name",
+			"service_id",
+			"status",
+			"type",
+		},
+		Changes: []change{
+			{
+				Previous:    "deployments_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	deploymentSourceInfo = metric{
+		Subsystem: "deployment",
+		Name:      "source_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the source (Docker image, git repository and commit, archive or database) of the Service's active Deployment",
+		Labels: []string{
+			"service_id",
+			"source_type",
+			"image",
+			"tag",
+			"repository",
+			"branch",
+			"sha",
+		},
+	}
+	deploymentImageAgeSeconds = metric{
+		Subsystem: "deployment",
+		Name:      "image_age_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time in seconds since the date encoded in the tag of the Service's active Deployment's source",
+		Labels: []string{
+			"service_id",
+			"tag",
+		},
+	}
+	// Domains
+	domainUp = metric{
+		Subsystem: "domain",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Domain is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"app_id",
+			"organization_id",
+			"name",
+			"status",
+			"type",
+		},
+		Changes: []change{
+			{
+				Previous:    "domains_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	domainVerified = metric{
+		Subsystem: "domain",
+		Name:      "verified",
+		Type:      gaugeMetric,
+		Help:      "1 if the Domain has been verified, 0 otherwise",
+		Labels: []string{
+			"id",
+			"name",
+			"type",
+		},
+	}
+	domainVerificationInfo = metric{
+		Subsystem: "domain",
+		Name:      "verification_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Domain's DNS verification state and the CNAME the Domain is intended to resolve to",
+		Labels: []string{
+			"id",
+			"name",
+			"status",
+			"intended_cname",
+		},
+	}
+	domainVerifiedTimestampSeconds = metric{
+		Subsystem: "domain",
+		Name:      "verified_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time at which the Domain was verified in Unix epoch seconds; only exported for verified Domains",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	domainCertificateExpiryTimestampSeconds = metric{
+		Subsystem: "domain",
+		Name:      "certificate_expiry_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Expiry time of the TLS certificate served by the Domain in Unix epoch seconds",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	domainSecondsUntilExpiry = metric{
+		Subsystem: "domain",
+		Name:      "seconds_until_expiry",
+		Type:      gaugeMetric,
+		Help:      "Number of seconds until the TLS certificate served by the Domain expires",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	domainRouteInfo = metric{
+		Subsystem: "domain",
+		Name:      "route_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Domain, the App and Service to which it routes, and the route's path and port",
+		Labels: []string{
+			"domain",
+			"app_id",
+			"service_id",
+			"path",
+			"port",
+		},
+	}
+	domainRoutes = metric{
+		Subsystem: "domain",
+		Name:      "routes",
+		Type:      gaugeMetric,
+		Help:      "Number of routes from the Domain to the active Deployments of its App's Services",
+		Labels: []string{
+			"domain",
+			"app_id",
+		},
+	}
+	// Drift
+	serviceDrift = metric{
+		Subsystem: "service",
+		Name:      "drift",
+		Type:      gaugeMetric,
+		Help:      "1 if the field of the Service's active Deployment definition differs from its desired definition, 0 otherwise",
+		Labels: []string{
+			"app",
+			"service",
+			"field",
+		},
+	}
+	// Environment variables
+	serviceEnvVarInfo = metric{
+		Subsystem: "service",
+		Name:      "env_var_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the name and kind (plain or secret) of an environment variable of the Service's active Deployment and the Secret that it references",
+		Labels: []string{
+			"service_id",
+			"name",
+			"kind",
+			"secret",
+		},
+	}
+	serviceEnvVars = metric{
+		Subsystem: "service",
+		Name:      "env_vars",
+		Type:      gaugeMetric,
+		Help:      "Number of environment variables of the Service's active Deployment by kind (plain or secret)",
+		Labels: []string{
+			"service_id",
+			"kind",
+		},
+	}
+	// Events
+	eventsTotal = metric{
+		Name: "events_total",
+		Type: counterMetric,
+		Help: "Total number of Koyeb events by type and resource kind",
+		Labels: []string{
+			"type",
+			"resource_kind",
+			"app_id",
+			"service_id",
+		},
+	}
+	serviceHealthCheckFailuresTotal = metric{
+		Subsystem: "service",
+		Name:      "health_check_failures_total",
+		Type:      counterMetric,
+		Help:      "Total number of failed health checks of the Service's Instances reported by Instance events",
+		Labels: []string{
+			"service_id",
+			"port",
+		},
+	}
+	// Exporter
+	exporterStartTimeSeconds = metric{
+		Subsystem: "exporter",
+		Name:      "start_time_seconds",
+		Type:      gaugeMetric,
+		Help:      "Exporter start time in Unix epoch seconds",
+		Changes: []change{
+			{
+				Previous:    "exporter_start_time",
+				Description: "The name ends with the metric's unit",
+			},
+		},
+	}
+	exporterBuildInfo = metric{
+		Subsystem: "exporter",
+		Name:      "build_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by OS version, Go version, and the Git commit of the exporter",
+		Labels: []string{
+			"os_version",
+			"go_version",
+			"git_commit",
+		},
+		Changes: []change{
+			{
+				Previous:    "exporter_build_info",
+				Description: "The metric is a gauge rather than a counter",
+			},
+		},
+	}
+	// Health checks
+	serviceHealthCheckInfo = metric{
+		Subsystem: "service",
+		Name:      "health_check_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the health check configured for the Service's port",
+		Labels: []string{
+			"service_id",
+			"protocol",
+			"port",
+			"path",
+			"grace_period",
+			"interval",
+			"restart_limit",
+			"timeout",
+		},
+	}
+	// HTTP probes
+	httpProbeStatusCode = metric{
+		Subsystem: "http_probe",
+		Name:      "status_code",
+		Type:      gaugeMetric,
+		Help:      "HTTP status code of the most recent probe of the endpoint (0 if the request failed)",
+		Labels: []string{
+			"endpoint",
+			"app_id",
+			"service_id",
+		},
+	}
+	httpProbeSuccess = metric{
+		Subsystem: "http_probe",
+		Name:      "success",
+		Type:      gaugeMetric,
+		Help:      "1 if the most recent probe of the endpoint returned a 2xx or 3xx status code, 0 otherwise",
+		Labels: []string{
+			"endpoint",
+			"app_id",
+			"service_id",
+		},
+	}
+	httpProbeCertificateExpiryTimestampSeconds = metric{
+		Subsystem: "http_probe",
+		Name:      "certificate_expiry_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Expiry time of the TLS certificate served by the endpoint in Unix epoch seconds",
+		Labels: []string{
+			"endpoint",
+			"app_id",
+			"service_id",
+		},
+	}
+	httpProbeDuration = metric{
+		Subsystem: "http_probe",
+		Name:      "duration_seconds",
+		Type:      histogramMetric,
+		Help:      "Duration of probes of the endpoint in seconds",
+		Labels: []string{
+			"endpoint",
+			"app_id",
+			"service_id",
+		},
+	}
+	// Instances
+	instanceUp = metric{
+		Subsystem: "instance",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Instance is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"app_id",
+			"service_id",
+			"organization_id",
+			"region",
+			"status",
+		},
+		Changes: []change{
+			{
+				Previous:    "instances_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	instanceAgeSeconds = metric{
+		Subsystem: "instance",
+		Name:      "age_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time in seconds since the Instance was created",
+		Labels: []string{
+			"id",
+			"service_id",
+		},
+	}
+	serviceInstanceReplacementsTotal = metric{
+		Subsystem: "service",
+		Name:      "instance_replacements_total",
+		Type:      counterMetric,
+		Help:      "Total number of times an Instance of the Service was replaced by another Instance",
+		Labels: []string{
+			"service_id",
+		},
+	}
+	serviceInstanceTerminationsTotal = metric{
+		Subsystem: "service",
+		Name:      "instance_terminations_total",
+		Type:      counterMetric,
+		Help:      "Total number of replaced Instances of the Service by termination reason",
+		Labels: []string{
+			"service_id",
+			"reason",
+		},
+	}
+	serviceCrashLoop = metric{
+		Subsystem: "service",
+		Name:      "crash_loop",
+		Type:      gaugeMetric,
+		Help:      "1 if the Service's Instances were replaced more than the threshold number of times within the window, 0 otherwise",
+		Labels: []string{
+			"service_id",
+		},
+	}
+	instanceCostPerHour = metric{
+		Subsystem: "instance",
+		Name:      "cost_per_hour",
+		Type:      gaugeMetric,
+		Help:      "Price per hour of the running Instance's instance type",
+		Labels: []string{
+			"id",
+			"app_id",
+			"service_id",
+			"type",
+		},
+	}
+	appEstimatedMonthlyCost = metric{
+		Subsystem: "app",
+		Name:      "estimated_monthly_cost",
+		Type:      gaugeMetric,
+		Help:      "Estimated monthly cost of the App's running Instances",
+		Labels: []string{
+			"app_id",
+		},
+	}
+	// Logs
+	logLinesTotal = metric{
+		Subsystem: "log",
+		Name:      "lines_total",
+		Type:      counterMetric,
+		Help:      "Total number of the Service's runtime log lines that match the rule by stream",
+		Labels: []string{
+			"service_id",
+			"stream",
+			"rule",
+		},
+	}
+	logBudgetExhaustedTotal = metric{
+		Subsystem: "log",
+		Name:      "budget_exhausted_total",
+		Type:      counterMetric,
+		Help:      "Total number of collections that exhausted the log line budget and skipped the remaining log lines",
+	}
+	// Organization
+	organizationInfo = metric{
+		Subsystem: "organization",
+		Name:      "info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the Organization's name, plan, status and deactivation reason",
+		Labels: []string{
+			"id",
+			"name",
+			"plan",
+			"status",
+			"detailed_status",
+			"deactivation_reason",
+		},
+	}
+	organizationLocked = metric{
+		Subsystem: "organization",
+		Name:      "locked",
+		Type:      gaugeMetric,
+		Help:      "1 if the Organization is locked, deactivated or being deleted, 0 otherwise",
+		Labels: []string{
+			"id",
+		},
+	}
+	organizationMembers = metric{
+		Subsystem: "organization",
+		Name:      "members",
+		Type:      gaugeMetric,
+		Help:      "Number of the Organization's members by role",
+		Labels: []string{
+			"id",
+			"role",
+		},
+	}
+	organizationPendingInvitations = metric{
+		Subsystem: "organization",
+		Name:      "pending_invitations",
+		Type:      gaugeMetric,
+		Help:      "Number of the Organization's pending invitations",
+		Labels: []string{
+			"id",
+		},
+	}
+	// Quotas
+	quotaLimit = metric{
+		Subsystem: "quota",
+		Name:      "limit",
+		Type:      gaugeMetric,
+		Help:      "Organization quota for the resource",
+		Labels: []string{
+			"resource",
+			"scope",
+		},
+	}
+	quotaUsed = metric{
+		Subsystem: "quota",
+		Name:      "used",
+		Type:      gaugeMetric,
+		Help:      "Organization's current usage of the resource",
+		Labels: []string{
+			"resource",
+			"scope",
+		},
+	}
+	quotaUtilization = metric{
+		Subsystem: "quota",
+		Name:      "utilization",
+		Type:      gaugeMetric,
+		Help:      "Ratio of the Organization's current usage of the resource to its quota",
+		Labels: []string{
+			"resource",
+			"scope",
+		},
+	}
+	// Regional Deployments
+	regionalDeploymentStatus = metric{
+		Subsystem: "regional_deployment",
+		Name:      "status",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the status of the Service's Regional Deployment",
+		Labels: []string{
+			"id",
+			"service_id",
+			"region",
+			"status",
+		},
+	}
+	regionalDeploymentDesiredInstances = metric{
+		Subsystem: "regional_deployment",
+		Name:      "desired_instances",
+		Type:      gaugeMetric,
+		Help:      "Minimum number of Instances of the Service's Regional Deployment",
+		Labels: []string{
+			"id",
+			"service_id",
+			"region",
+		},
+	}
+	regionalDeploymentRunningInstances = metric{
+		Subsystem: "regional_deployment",
+		Name:      "running_instances",
+		Type:      gaugeMetric,
+		Help:      "Number of running Instances of the Service's Regional Deployment",
+		Labels: []string{
+			"id",
+			"service_id",
+			"region",
+		},
+	}
+	serviceRegionsDesired = metric{
+		Subsystem: "service",
+		Name:      "regions_desired",
+		Type:      gaugeMetric,
+		Help:      "Number of regions in which the Service's active Deployment is defined",
+		Labels: []string{
+			"service_id",
+		},
+	}
+	serviceRegionsHealthy = metric{
+		Subsystem: "service",
+		Name:      "regions_healthy",
+		Type:      gaugeMetric,
+		Help:      "Number of regions in which the Service's active Deployment is healthy",
+		Labels: []string{
+			"service_id",
+		},
+	}
+	regionInfo = metric{
+		Subsystem: "region",
+		Name:      "info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by the region's name, status and scope",
+		Labels: []string{
+			"id",
+			"name",
+			"status",
+			"scope",
+			"volumes_enabled",
+		},
+	}
+	// Secrets
+	secretUp = metric{
+		Subsystem: "secret",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Secret is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"organization_id",
+			"name",
+			"type",
+			"registry",
+		},
+		Changes: []change{
+			{
+				Previous:    "secrets_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	secretByRegistry = metric{
+		Subsystem: "secret",
+		Name:      "by_registry",
+		Type:      gaugeMetric,
+		Help:      "Number of Secrets by registry type; Secrets whose registry type is unknown are counted as undefined",
+		Labels: []string{
+			"registry",
+		},
+	}
+	secretReferencedByServices = metric{
+		Subsystem: "secret",
+		Name:      "referenced_by_services",
+		Type:      gaugeMetric,
+		Help:      "Number of Services whose active Deployment references the Secret",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	secretUnused = metric{
+		Subsystem: "secret",
+		Name:      "unused",
+		Type:      gaugeMetric,
+		Help:      "1 if the Secret is not referenced by any Service's active Deployment, 0 otherwise",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	secretCreatedTimestampSeconds = metric{
+		Subsystem: "secret",
+		Name:      "created_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time the Secret was created in Unix epoch seconds",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	secretLastUpdatedTimestampSeconds = metric{
+		Subsystem: "secret",
+		Name:      "last_updated_timestamp_seconds",
+		Type:      gaugeMetric,
+		Help:      "Time the Secret was last updated in Unix epoch seconds",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	secretRotationOverdue = metric{
+		Subsystem: "secret",
+		Name:      "rotation_overdue",
+		Type:      gaugeMetric,
+		Help:      "1 if the Secret has not been updated within the maximum age, 0 otherwise",
+		Labels: []string{
+			"id",
+			"name",
+		},
+	}
+	// Services
+	serviceUp = metric{
+		Subsystem: "service",
+		Name:      "up",
+		Type:      gaugeMetric,
+		Help:      "1 if the Service is up, 0 otherwise",
+		Labels: []string{
+			"id",
+			"app_id",
+			"organization_id",
+			"name",
+			"status",
+		},
+		Changes: []change{
+			{
+				Previous:    "services_up",
+				Description: "Metrics are prefixed by the singular name of their resource",
+			},
+		},
+	}
+	servicePortInfo = metric{
+		Subsystem: "service",
+		Name:      "port_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by a port and protocol of the Service's active Deployment and whether the port is publicly reachable",
+		Labels: []string{
+			"service_id",
+			"port",
+			"protocol",
+			"public",
+		},
+	}
+	serviceRouteInfo = metric{
+		Subsystem: "service",
+		Name:      "route_info",
+		Type:      gaugeMetric,
+		Help:      "A metric with a constant '1' value labeled by a public route (path) of the Service's active Deployment and the port to which it routes",
+		Labels: []string{
+			"service_id",
+			"path",
+			"port",
+		},
+	}
+	servicePublicTCP = metric{
+		Subsystem: "service",
+		Name:      "public_tcp",
+		Type:      gaugeMetric,
+		Help:      "1 if the Service's active Deployment exposes a TCP port publicly, 0 otherwise",
+		Labels: []string{
+			"service_id",
+		},
+	}
+)
+
+// metrics is the list of every metric exported by the collectors
+var metrics = []metric{
+	appUp,
+	organizationPlanInfo,
+	billingSubscriptionInfo,
+	billingNextInvoiceAmount,
+	billingAmountPayable,
+	billingAmountPaid,
+	billingAmountRemaining,
+	billingUnpaidInvoices,
+	billingPaymentFailed,
+	billingTrialing,
+	billingTrialEndTimestampSeconds,
+	billingTrialMaxSpend,
+	billingCurrentSpend,
+	billingTrialCreditBalance,
+	budgetLimit,
+	budgetSpent,
+	budgetProjected,
+	buildFailuresTotal,
+	credentialUp,
+	credentialInfo,
+	credentialByUser,
+	credentialIsExporterToken,
+	credentialCreatedTimestampSeconds,
+	credentialLastUpdatedTimestampSeconds,
+	deploymentUp,
+	deploymentSourceInfo,
+	deploymentImageAgeSeconds,
+	domainUp,
+	domainVerified,
+	domainVerificationInfo,
+	domainVerifiedTimestampSeconds,
+	domainCertificateExpiryTimestampSeconds,
+	domainSecondsUntilExpiry,
+	domainRouteInfo,
+	domainRoutes,
+	serviceDrift,
+	serviceEnvVarInfo,
+	serviceEnvVars,
+	eventsTotal,
+	serviceHealthCheckFailuresTotal,
+	exporterStartTimeSeconds,
+	exporterBuildInfo,
+	serviceHealthCheckInfo,
+	httpProbeStatusCode,
+	httpProbeSuccess,
+	httpProbeCertificateExpiryTimestampSeconds,
+	httpProbeDuration,
+	instanceUp,
+	instanceAgeSeconds,
+	serviceInstanceReplacementsTotal,
+	serviceInstanceTerminationsTotal,
+	serviceCrashLoop,
+	instanceCostPerHour,
+	appEstimatedMonthlyCost,
+	logLinesTotal,
+	logBudgetExhaustedTotal,
+	organizationInfo,
+	organizationLocked,
+	organizationMembers,
+	organizationPendingInvitations,
+	quotaLimit,
+	quotaUsed,
+	quotaUtilization,
+	regionalDeploymentStatus,
+	regionalDeploymentDesiredInstances,
+	regionalDeploymentRunningInstances,
+	serviceRegionsDesired,
+	serviceRegionsHealthy,
+	regionInfo,
+	secretUp,
+	secretByRegistry,
+	secretReferencedByServices,
+	secretUnused,
+	secretCreatedTimestampSeconds,
+	secretLastUpdatedTimestampSeconds,
+	secretRotationOverdue,
+	serviceUp,
+	servicePortInfo,
+	serviceRouteInfo,
+	servicePublicTCP,
+}
+
+// markdown is a function that returns the README's table of metrics
+// Metric names exclude the namespace and are sorted alphabetically
+func markdown() string {
+	sorted := make([]metric, len(metrics))
+	copy(sorted, metrics)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FQName() < sorted[j].FQName()
+	})
+
+	var b strings.Builder
+	b.WriteString("|Name|Type|Description|\n")
+	b.WriteString("|----|----|-----------|\n")
+	for _, m := range sorted {
+		name := strings.TrimPrefix(m.FQName(), namespace+"_")
+		fmt.Fprintf(&b, "|`%s`|%s|%s|\n", name, m.Type, m.Help)
+	}
+
+	return b.String()
+}
+
+// changes is a function that returns the README's table of changed metrics as Markdown
+func changes() string {
+	sorted := make([]metric, len(metrics))
+	copy(sorted, metrics)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FQName() < sorted[j].FQName()
+	})
+
+	var b strings.Builder
+	b.WriteString("|Previous name|Name|Change|\n")
+	b.WriteString("|-------------|----|------|\n")
+	for _, m := range sorted {
+		name := strings.TrimPrefix(m.FQName(), namespace+"_")
+		for _, c := range m.Changes {
+			fmt.Fprintf(&b, "|`%s`|`%s`|%s|\n", c.Previous, name, c.Description)
+		}
+	}
+
+	return b.String()
+}
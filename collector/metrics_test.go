@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var update = flag.Bool("update", false, "update the README's tables of metrics and changed metrics")
+
+const (
+	readme       = "../README.md"
+	tableBegin   = "<!-- metrics:begin -->\n"
+	tableEnd     = "<!-- metrics:end -->\n"
+	changesBegin = "<!-- changes:begin -->\n"
+	changesEnd   = "<!-- changes:end -->\n"
+	sampleValue  = "x"
+)
+
+// definitions is a type that implements prometheus.Collector
+// It emits one sample of each metric definition
+type definitions []metric
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (d definitions) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range d {
+		labelValues := make([]string, len(m.Labels))
+		for i := range labelValues {
+			labelValues[i] = sampleValue
+		}
+
+		switch m.Type {
+		case gaugeMetric, counterMetric:
+			ch <- constMetric(m.Desc(), 1, labelValues...)
+		case histogramMetric:
+			ch <- prometheus.MustNewConstHistogram(m.Desc(), 1, 1, map[float64]uint64{1: 1}, labelValues...)
+		}
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (d definitions) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range d {
+		ch <- m.Desc()
+	}
+}
+
+func TestMetricsLint(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(definitions(metrics)); err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != len(metrics) {
+		t.Errorf("got %d metric families, want %d", len(mfs), len(metrics))
+	}
+
+	problems, err := promlint.NewWithMetricFamilies(mfs).Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("%s: %s", p.Metric, p.Text)
+	}
+}
+
+func TestMetricsTypes(t *testing.T) {
+	for _, m := range metrics {
+		switch m.Type {
+		case gaugeMetric, counterMetric, histogramMetric:
+		default:
+			t.Errorf("%s: unexpected type %q", m.FQName(), m.Type)
+		}
+
+		if m.Help == "" {
+			t.Errorf("%s: no help", m.FQName())
+		}
+	}
+}
+
+func TestConstMetric(t *testing.T) {
+	for _, m := range []metric{appUp, eventsTotal} {
+		metric := &dto.Metric{}
+		if err := constMetric(m.Desc(), 1, make([]string, len(m.Labels))...).Write(metric); err != nil {
+			t.Fatal(err)
+		}
+
+		// The value type is that of the metric definition
+		switch m.Type {
+		case gaugeMetric:
+			if metric.Gauge == nil {
+				t.Errorf("%s: got %v, want a gauge", m.FQName(), metric)
+			}
+		case counterMetric:
+			if metric.Counter == nil {
+				t.Errorf("%s: got %v, want a counter", m.FQName(), metric)
+			}
+		}
+	}
+
+	// Metrics that are not defined are rejected
+	defer func() {
+		if recover() == nil {
+			t.Error("got no panic for an undefined metric, want panic")
+		}
+	}()
+	constMetric(prometheus.NewDesc("undefined", "Undefined", nil, nil), 1)
+}
+
+func TestConstMetricsOnly(t *testing.T) {
+	// Collectors must not choose the value types of the metrics that they emit (see constMetric)
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file == "metrics.go" || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, value := range []string{"prometheus.GaugeValue", "prometheus.CounterValue", "prometheus.UntypedValue"} {
+			if strings.Contains(string(b), value) {
+				t.Errorf("%s: uses %s rather than constMetric", file, value)
+			}
+		}
+	}
+}
+
+func TestMetricsNames(t *testing.T) {
+	for _, m := range metrics {
+		// Subsystems are the singular name of the resource (e.g. "domain") or feature (e.g. "http_probe")
+		if strings.HasSuffix(m.Subsystem, "s") {
+			t.Errorf("%s: subsystem %q is not singular", m.FQName(), m.Subsystem)
+		}
+
+		// Times are in seconds and the name ends with the unit
+		if strings.Contains(m.Name, "time") && !strings.HasSuffix(m.Name, "_seconds") {
+			t.Errorf("%s: name does not end with the unit (_seconds)", m.FQName())
+		}
+	}
+}
+
+func TestMetricsChanges(t *testing.T) {
+	// Previous names must not be the names of other metrics
+	names := map[string]string{}
+	for _, m := range metrics {
+		names[strings.TrimPrefix(m.FQName(), namespace+"_")] = m.FQName()
+	}
+	for _, m := range metrics {
+		for _, c := range m.Changes {
+			if c.Description == "" {
+				t.Errorf("%s: change from %q has no description", m.FQName(), c.Previous)
+			}
+			if other, ok := names[c.Previous]; ok && other != m.FQName() {
+				t.Errorf("%s: previous name %q is the name of %s", m.FQName(), c.Previous, other)
+			}
+		}
+	}
+}
+
+func TestMetricsREADME(t *testing.T) {
+	b, err := os.ReadFile(readme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+
+	for _, table := range []struct {
+		begin string
+		end   string
+		want  string
+	}{
+		{begin: tableBegin, end: tableEnd, want: markdown()},
+		{begin: changesBegin, end: changesEnd, want: changes()},
+	} {
+		begin := strings.Index(s, table.begin)
+		end := strings.Index(s, table.end)
+		if begin == -1 || end == -1 || end < begin {
+			t.Fatalf("README does not contain %q followed by %q", table.begin, table.end)
+		}
+		begin += len(table.begin)
+
+		if got := s[begin:end]; got != table.want {
+			if !*update {
+				t.Fatal("README's tables of metrics are out of date; run `go test ./collector -run TestMetricsREADME -update`")
+			}
+			s = s[:begin] + table.want + s[end:]
+		}
+	}
+
+	if *update {
+		if err := os.WriteFile(readme, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
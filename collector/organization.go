@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that OrganizationCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*OrganizationCollector)(nil)
+
+// OrganizationCollector collects Koyeb Organization metrics
+type OrganizationCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	Info               *prometheus.Desc
+	Locked             *prometheus.Desc
+	Members            *prometheus.Desc
+	PendingInvitations *prometheus.Desc
+}
+
+// NewOrganizationCollector is a function that creates a new OrganizationCollector
+func NewOrganizationCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *OrganizationCollector {
+	subsystem := "organization"
+	logger := l.With("collector", subsystem)
+
+	return &OrganizationCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		Info:               organizationInfo.Desc(),
+		Locked:             organizationLocked.Desc(),
+		Members:            organizationMembers.Desc(),
+		PendingInvitations: organizationPendingInvitations.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *OrganizationCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	rqst := c.client.ProfileApi.GetCurrentOrganization(c.ctx)
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		msg := "unable to get Organization"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	organization := resp.GetOrganization()
+	id := organization.GetId()
+
+	ch <- constMetric(
+		c.Info,
+		1.0,
+		[]string{
+			id,
+			organization.GetName(),
+			string(organization.GetPlan()),
+			string(organization.GetStatus()),
+			string(organization.GetStatusMessage()),
+			string(organization.GetDeactivationReason()),
+		}...,
+	)
+
+	locked := 0.0
+	switch organization.GetStatus() {
+	case koyeb.ORGANIZATIONSTATUS_LOCKED,
+		koyeb.ORGANIZATIONSTATUS_DEACTIVATING,
+		koyeb.ORGANIZATIONSTATUS_DEACTIVATED,
+		koyeb.ORGANIZATIONSTATUS_DELETING,
+		koyeb.ORGANIZATIONSTATUS_DELETED:
+		locked = 1.0
+	}
+	ch <- constMetric(
+		c.Locked,
+		locked,
+		[]string{
+			id,
+		}...,
+	)
+
+	c.collectMembers(ch, id)
+	c.collectInvitations(ch, id)
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *OrganizationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Info
+	ch <- c.Locked
+	ch <- c.Members
+	ch <- c.PendingInvitations
+}
+
+// collectMembers is a method that counts the Organization's members by role
+func (c *OrganizationCollector) collectMembers(ch chan<- prometheus.Metric, id string) {
+	logger := c.logger.With("method", "collectMembers")
+
+	members, err := listAll(func(limit, offset string) ([]koyeb.OrganizationMember, bool, error) {
+		rqst := c.client.OrganizationMembersApi.ListOrganizationMembers(c.ctx).
+			OrganizationId(id).
+			Limit(limit).
+			Offset(offset)
+		resp, _, err := rqst.Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Members, resp.GetOffset()+int64(len(resp.Members)) < resp.GetCount(), nil
+	})
+	if err != nil {
+		logger.Error("unable to list Organization members", "err", err)
+		return
+	}
+
+	// Number of members keyed by role
+	roles := map[string]int{}
+	for _, member := range members {
+		roles[string(member.GetRole())]++
+	}
+
+	for role, count := range roles {
+		ch <- constMetric(
+			c.Members,
+			float64(count),
+			[]string{
+				id,
+				role,
+			}...,
+		)
+	}
+}
+
+// collectInvitations is a method that counts the Organization's pending invitations
+func (c *OrganizationCollector) collectInvitations(ch chan<- prometheus.Metric, id string) {
+	logger := c.logger.With("method", "collectInvitations")
+
+	rqst := c.client.OrganizationInvitationsApi.ListOrganizationInvitations(c.ctx).
+		Statuses([]string{
+			string(koyeb.ORGANIZATIONINVITATIONSTATUS_PENDING),
+		}).
+		Limit("1")
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		logger.Error("unable to list Organization invitations", "err", err)
+		return
+	}
+
+	ch <- constMetric(
+		c.PendingInvitations,
+		float64(resp.GetCount()),
+		[]string{
+			id,
+		}...,
+	)
+}
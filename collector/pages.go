@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+const (
+	// pageSize is the number of resources requested per page when listing every resource
+	pageSize int = 100
+	// maxPages bounds the number of pages requested when listing every resource
+	maxPages int = 100
+)
+
+// listAll is a function that lists every resource by requesting pages with increasing offsets
+// list is called with the page's limit and offset and returns the page's resources and whether there are more pages
+func listAll[T any](list func(limit, offset string) ([]T, bool, error)) ([]T, error) {
+	all := []T{}
+	for page := 0; page < maxPages; page++ {
+		items, hasNext, err := list(strconv.Itoa(pageSize), strconv.Itoa(page*pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, items...)
+		if !hasNext || len(items) == 0 {
+			break
+		}
+	}
+	return all, nil
+}
+
+// listServices is a function that lists every Service
+func listServices(ctx context.Context, client *koyeb.APIClient) ([]koyeb.ServiceListItem, error) {
+	return listAll(func(limit, offset string) ([]koyeb.ServiceListItem, bool, error) {
+		resp, _, err := client.ServicesApi.ListServices(ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Services, resp.GetHasNext(), nil
+	})
+}
+
+// listApps is a function that lists every App
+func listApps(ctx context.Context, client *koyeb.APIClient) ([]koyeb.AppListItem, error) {
+	return listAll(func(limit, offset string) ([]koyeb.AppListItem, bool, error) {
+		resp, _, err := client.AppsApi.ListApps(ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Apps, resp.GetHasNext(), nil
+	})
+}
+
+// listInstances is a function that lists every Instance
+func listInstances(ctx context.Context, client *koyeb.APIClient) ([]koyeb.InstanceListItem, error) {
+	return listAll(func(limit, offset string) ([]koyeb.InstanceListItem, bool, error) {
+		resp, _, err := client.InstancesApi.ListInstances(ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Instances, resp.GetOffset()+int64(len(resp.Instances)) < resp.GetCount(), nil
+	})
+}
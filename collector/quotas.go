@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/catalog"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that QuotasCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*QuotasCollector)(nil)
+
+// quotaKey identifies a quota by its resource and, for quotas that apply per instance type or region, its scope
+type quotaKey struct {
+	resource string
+	scope    string
+}
+
+// QuotasCollector collects Koyeb Organization quotas and their current usage
+type QuotasCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// catalog provides the memory of instance types
+	catalog *catalog.Catalog
+
+	Limit       *prometheus.Desc
+	Used        *prometheus.Desc
+	Utilization *prometheus.Desc
+}
+
+// NewQuotasCollector is a function that creates a new QuotasCollector
+func NewQuotasCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, catalog *catalog.Catalog) *QuotasCollector {
+	subsystem := "quota"
+	logger := l.With("collector", subsystem)
+
+	return &QuotasCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		catalog: catalog,
+
+		Limit:       quotaLimit.Desc(),
+		Used:        quotaUsed.Desc(),
+		Utilization: quotaUtilization.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *QuotasCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	organization, _, err := c.client.ProfileApi.GetCurrentOrganization(c.ctx).Execute()
+	if err != nil {
+		msg := "unable to get Organization"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	organizationID := organization.Organization.GetId()
+
+	rqst := c.client.OrganizationQuotasApi.GetQuotas(c.ctx, organizationID)
+	resp, _, err := rqst.Execute()
+	if err != nil {
+		msg := "unable to get Quotas"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	limits := c.limits(resp.GetQuotas())
+	used, determined := c.used(organizationID)
+
+	for key, limit := range limits {
+		labels := []string{
+			key.resource,
+			key.scope,
+		}
+
+		ch <- constMetric(
+			c.Limit,
+			limit,
+			labels...,
+		)
+
+		// Usage is only reported for resources whose usage was determined
+		// Scopes without usage (e.g. instance types with no running Instances) are zero
+		if !determined[key.resource] {
+			continue
+		}
+		value := used[key]
+
+		ch <- constMetric(
+			c.Used,
+			value,
+			labels...,
+		)
+		if limit > 0 {
+			ch <- constMetric(
+				c.Utilization,
+				value/limit,
+				labels...,
+			)
+		}
+	}
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *QuotasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Limit
+	ch <- c.Used
+	ch <- c.Utilization
+}
+
+// limits is a method that converts the Organization's quotas into limits keyed by resource and scope
+// Quotas that are not set are omitted
+func (c *QuotasCollector) limits(quotas koyeb.Quotas) map[quotaKey]float64 {
+	limits := map[quotaKey]float64{}
+
+	// Whole-Organization quotas
+	for resource, value := range map[string]*string{
+		"apps":                 quotas.Apps,
+		"services":             quotas.Services,
+		"domains":              quotas.Domains,
+		"custom_domains":       quotas.CustomDomains,
+		"memory_mb":            quotas.MemoryMb,
+		"organization_members": quotas.MaxOrganizationMembers,
+	} {
+		if value == nil {
+			continue
+		}
+		if limit, err := strconv.ParseFloat(*value, 64); err == nil {
+			limits[quotaKey{resource: resource}] = limit
+		}
+	}
+
+	for instanceType, value := range quotas.GetMaxInstancesByType() {
+		if limit, err := strconv.ParseFloat(value, 64); err == nil {
+			limits[quotaKey{resource: "instances", scope: instanceType}] = limit
+		}
+	}
+
+	for region, volumes := range quotas.GetPersistentVolumesByRegion() {
+		if size, ok := volumes.GetMaxTotalSizeOk(); ok {
+			limits[quotaKey{resource: "persistent_volumes_gb", scope: region}] = float64(*size)
+		}
+	}
+
+	return limits
+}
+
+// used is a method that determines the Organization's current usage of resources keyed by resource and scope
+// Returns the usage and the set of resources whose usage was determined; resources that cannot be listed are not determined
+func (c *QuotasCollector) used(organizationID string) (map[quotaKey]float64, map[string]bool) {
+	logger := c.logger.With("method", "used")
+
+	used := map[quotaKey]float64{}
+	determined := map[string]bool{}
+
+	if resp, _, err := c.client.AppsApi.ListApps(c.ctx).Limit("1").Execute(); err != nil {
+		logger.Info("unable to list Apps", "err", err)
+	} else {
+		determined["apps"] = true
+		used[quotaKey{resource: "apps"}] = float64(resp.GetCount())
+	}
+
+	if services, err := listServices(c.ctx, c.client); err != nil {
+		logger.Info("unable to list Services", "err", err)
+	} else {
+		determined["services"] = true
+		used[quotaKey{resource: "services"}] = float64(len(services))
+	}
+
+	if domains, err := listAll(func(limit, offset string) ([]koyeb.Domain, bool, error) {
+		resp, _, err := c.client.DomainsApi.ListDomains(c.ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Domains, resp.GetOffset()+int64(len(resp.Domains)) < resp.GetCount(), nil
+	}); err != nil {
+		logger.Info("unable to list Domains", "err", err)
+	} else {
+		custom := 0
+		for _, domain := range domains {
+			if domain.GetType() == koyeb.DOMAINTYPE_CUSTOM {
+				custom++
+			}
+		}
+		determined["domains"] = true
+		determined["custom_domains"] = true
+		used[quotaKey{resource: "domains"}] = float64(len(domains))
+		used[quotaKey{resource: "custom_domains"}] = float64(custom)
+	}
+
+	if instances, err := listInstances(c.ctx, c.client); err != nil {
+		logger.Info("unable to list Instances", "err", err)
+	} else {
+		memory := 0.0
+		for _, instance := range instances {
+			if !running(instance.GetStatus()) {
+				continue
+			}
+
+			used[quotaKey{resource: "instances", scope: instance.GetType()}]++
+
+			if t, ok := c.catalog.Lookup(instance.GetType()); ok {
+				memory += t.MemoryMB()
+			}
+		}
+		determined["instances"] = true
+		determined["memory_mb"] = true
+		used[quotaKey{resource: "memory_mb"}] = memory
+	}
+
+	if volumes, err := listAll(func(limit, offset string) ([]koyeb.PersistentVolume, bool, error) {
+		resp, _, err := c.client.PersistentVolumesApi.ListPersistentVolumes(c.ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Volumes, resp.GetHasNext(), nil
+	}); err != nil {
+		logger.Info("unable to list Persistent Volumes", "err", err)
+	} else {
+		determined["persistent_volumes_gb"] = true
+		for _, volume := range volumes {
+			used[quotaKey{resource: "persistent_volumes_gb", scope: volume.GetRegion()}] += float64(volume.GetMaxSize())
+		}
+	}
+
+	if resp, _, err := c.client.OrganizationMembersApi.ListOrganizationMembers(c.ctx).OrganizationId(organizationID).Limit("1").Execute(); err != nil {
+		logger.Info("unable to list Organization members", "err", err)
+	} else {
+		determined["organization_members"] = true
+		used[quotaKey{resource: "organization_members"}] = float64(resp.GetCount())
+	}
+
+	return used, determined
+}
@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/DazWilkin/go-probe/probe"
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure that RegionalDeploymentsCollector implements Prometheus' Collector interface
+var _ prometheus.Collector = (*RegionalDeploymentsCollector)(nil)
+
+// RegionalDeploymentsCollector collects Koyeb Regional Deployments metrics for each Service's active Deployment
+type RegionalDeploymentsCollector struct {
+	ctx    context.Context
+	client *koyeb.APIClient
+	ch     chan<- probe.Status
+	logger *slog.Logger
+
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	Status         *prometheus.Desc
+	Desired        *prometheus.Desc
+	Running        *prometheus.Desc
+	RegionsDesired *prometheus.Desc
+	RegionsHealthy *prometheus.Desc
+	RegionInfo     *prometheus.Desc
+}
+
+// NewRegionalDeploymentsCollector is a function that creates a new RegionalDeploymentsCollector
+func NewRegionalDeploymentsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments) *RegionalDeploymentsCollector {
+	subsystem := "regional_deployment"
+	logger := l.With("collector", subsystem)
+
+	return &RegionalDeploymentsCollector{
+		ctx:    ctx,
+		client: client,
+		ch:     ch,
+		logger: logger,
+
+		actives: actives,
+
+		Status:         regionalDeploymentStatus.Desc(),
+		Desired:        regionalDeploymentDesiredInstances.Desc(),
+		Running:        regionalDeploymentRunningInstances.Desc(),
+		RegionsDesired: serviceRegionsDesired.Desc(),
+		RegionsHealthy: serviceRegionsHealthy.Desc(),
+		RegionInfo:     regionInfo.Desc(),
+	}
+}
+
+// Collect implements Prometheus' Collector interface and is used to collect metrics
+func (c *RegionalDeploymentsCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collect")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		msg := "unable to list active Deployments"
+		logger.Error(msg, "err", err)
+
+		// Send probe unhealthy status
+		// Doesn't surface the API error message (should it!?)
+		status := probe.Status{
+			Healthy: false,
+			Message: msg,
+		}
+		c.ch <- status
+
+		return
+	}
+
+	// Send probe healthy status
+	status := probe.Status{
+		Healthy: true,
+		Message: "ok",
+	}
+	c.ch <- status
+
+	// Number of running Instances keyed by Regional Deployment ID
+	// Running Instances are only reported if the Instances can be listed
+	running, err := c.running()
+	if err != nil {
+		logger.Info("unable to list Instances", "err", err)
+	}
+
+	for _, active := range actives {
+		serviceID := active.Service.GetId()
+		deploymentID := active.Deployment.GetId()
+
+		regionals, err := listAll(func(limit, offset string) ([]koyeb.RegionalDeploymentListItem, bool, error) {
+			rqst := c.client.RegionalDeploymentsApi.ListRegionalDeployments(c.ctx).
+				DeploymentId(deploymentID).
+				Limit(limit).
+				Offset(offset)
+			resp, _, err := rqst.Execute()
+			if err != nil {
+				return nil, false, err
+			}
+			return resp.RegionalDeployments, resp.GetHasNext(), nil
+		})
+		if err != nil {
+			logger.Error("unable to list Regional Deployments",
+				"deployment_id", deploymentID,
+				"err", err,
+			)
+			continue
+		}
+
+		healthy := 0
+		for _, regional := range regionals {
+			if regional.GetStatus() == koyeb.REGIONALDEPLOYMENTSTATUS_HEALTHY {
+				healthy++
+			}
+
+			labels := []string{
+				regional.GetId(),
+				serviceID,
+				regional.GetRegion(),
+			}
+
+			ch <- constMetric(
+				c.Status,
+				1.0,
+				append(labels, string(regional.GetStatus()))...,
+			)
+
+			definition := regional.GetDefinition()
+			scaling := definition.GetScaling()
+			ch <- constMetric(
+				c.Desired,
+				float64(scaling.GetMin()),
+				labels...,
+			)
+
+			if running != nil {
+				ch <- constMetric(
+					c.Running,
+					float64(running[regional.GetId()]),
+					labels...,
+				)
+			}
+		}
+
+		definition := active.Definition()
+		ch <- constMetric(
+			c.RegionsDesired,
+			float64(len(definition.GetRegions())),
+			[]string{
+				serviceID,
+			}...,
+		)
+		ch <- constMetric(
+			c.RegionsHealthy,
+			float64(healthy),
+			[]string{
+				serviceID,
+			}...,
+		)
+	}
+
+	c.collectRegions(ch)
+}
+
+// Describe implements Prometheus' Collector interface and is used to describe metrics
+func (c *RegionalDeploymentsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Status
+	ch <- c.Desired
+	ch <- c.Running
+	ch <- c.RegionsDesired
+	ch <- c.RegionsHealthy
+	ch <- c.RegionInfo
+}
+
+// running is a method that counts the running Instances keyed by their Regional Deployment ID
+func (c *RegionalDeploymentsCollector) running() (map[string]int, error) {
+	instances, err := listInstances(c.ctx, c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, instance := range instances {
+		if running(instance.GetStatus()) {
+			counts[instance.GetRegionalDeploymentId()]++
+		}
+	}
+	return counts, nil
+}
+
+// collectRegions is a method that collects the regions available in the catalog
+func (c *RegionalDeploymentsCollector) collectRegions(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collectRegions")
+
+	regions, err := listAll(func(limit, offset string) ([]koyeb.RegionListItem, bool, error) {
+		resp, _, err := c.client.CatalogRegionsApi.ListRegions(c.ctx).Limit(limit).Offset(offset).Execute()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Regions, resp.GetOffset()+int64(len(resp.Regions)) < resp.GetCount(), nil
+	})
+	if err != nil {
+		logger.Error("unable to list Regions", "err", err)
+		return
+	}
+
+	for _, region := range regions {
+		ch <- constMetric(
+			c.RegionInfo,
+			1.0,
+			[]string{
+				region.GetId(),
+				region.GetName(),
+				region.GetStatus(),
+				region.GetScope(),
+				strconv.FormatBool(region.GetVolumesEnabled()),
+			}...,
+		)
+	}
+}
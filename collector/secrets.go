@@ -3,6 +3,7 @@ package collector
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/DazWilkin/go-probe/probe"
 	"github.com/DazWilkin/koyeb-exporter/types"
@@ -20,11 +21,24 @@ type SecretsCollector struct {
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	// Secrets not updated within maxAge are overdue rotation
+	maxAge time.Duration
+
+	Up                   *prometheus.Desc
+	ByRegistry           *prometheus.Desc
+	ReferencedByServices *prometheus.Desc
+	Unused               *prometheus.Desc
+	Created              *prometheus.Desc
+	LastUpdated          *prometheus.Desc
+	RotationOverdue      *prometheus.Desc
 }
 
 // NewSecretsCollector is a function that creates a new SecretsCollector
-func NewSecretsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *SecretsCollector {
+// A Secret is overdue rotation when it has not been updated (or created) within maxAge
+func NewSecretsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments, maxAge time.Duration) *SecretsCollector {
 	subsystem := "secrets"
 	logger := l.With("collector", subsystem)
 
@@ -34,18 +48,17 @@ func NewSecretsCollector(ctx context.Context, client *koyeb.APIClient, ch chan<-
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the Secret is up, 0 otherwise",
-			[]string{
-				"id",
-				"organization_id",
-				"name",
-				"type",
-				"registry", // Synthetic
-			},
-			nil,
-		),
+		actives: actives,
+
+		maxAge: maxAge,
+
+		Up:                   secretUp.Desc(),
+		ByRegistry:           secretByRegistry.Desc(),
+		ReferencedByServices: secretReferencedByServices.Desc(),
+		Unused:               secretUnused.Desc(),
+		Created:              secretCreatedTimestampSeconds.Desc(),
+		LastUpdated:          secretLastUpdatedTimestampSeconds.Desc(),
+		RotationOverdue:      secretRotationOverdue.Desc(),
 	}
 }
 
@@ -77,10 +90,16 @@ func (c *SecretsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 	c.ch <- status
 
+	now := time.Now()
+
+	// Number of Secrets keyed by registry type
+	registries := map[types.RegistryType]int{}
+
 	for _, secret := range resp.Secrets {
-		ch <- prometheus.MustNewConstMetric(
+		registries[types.GetRegistryType(secret)]++
+
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				secret.GetId(),
@@ -90,11 +109,125 @@ func (c *SecretsCollector) Collect(ch chan<- prometheus.Metric) {
 				types.GetRegistryType(secret).String(),
 			}...,
 		)
+
+		c.collectAge(ch, secret, now)
+	}
+
+	// Secrets whose registry type can't be determined are counted as undefined so that the counts sum to the number of Secrets
+	for _, registryType := range append([]types.RegistryType{types.Undefined}, types.RegistryTypes...) {
+		registry := registryType.String()
+		if registryType == types.Undefined {
+			registry = "undefined"
+		}
+		ch <- constMetric(
+			c.ByRegistry,
+			float64(registries[registryType]),
+			[]string{
+				registry,
+			}...,
+		)
+	}
+
+	c.collectReferences(ch, resp.Secrets)
+}
+
+// collectAge is a method that collects the Secret's creation and update times and whether it is overdue rotation
+// A Secret that has never been updated is treated as last updated when it was created
+func (c *SecretsCollector) collectAge(ch chan<- prometheus.Metric, secret koyeb.Secret, now time.Time) {
+	labels := []string{
+		secret.GetId(),
+		secret.GetName(),
+	}
+
+	if secret.CreatedAt != nil {
+		ch <- constMetric(
+			c.Created,
+			float64(secret.GetCreatedAt().Unix()),
+			labels...,
+		)
 	}
 
+	updated, ok := secret.GetUpdatedAtOk()
+	if !ok || updated.IsZero() {
+		updated, ok = secret.GetCreatedAtOk()
+	}
+	if !ok {
+		return
+	}
+
+	ch <- constMetric(
+		c.LastUpdated,
+		float64(updated.Unix()),
+		labels...,
+	)
+
+	if c.maxAge <= 0 {
+		return
+	}
+
+	overdue := 0.0
+	if now.Sub(*updated) > c.maxAge {
+		overdue = 1.0
+	}
+	ch <- constMetric(
+		c.RotationOverdue,
+		overdue,
+		labels...,
+	)
+}
+
+// collectReferences is a method that cross-references Secrets against the active Deployment of every Service
+func (c *SecretsCollector) collectReferences(ch chan<- prometheus.Metric, secrets []koyeb.Secret) {
+	logger := c.logger.With("method", "collectReferences")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		logger.Error("unable to list active Deployments", "err", err)
+		return
+	}
+
+	// Number of Services referencing each Secret keyed by the Secret's name
+	services := map[string]int{}
+	for _, active := range actives {
+		for name := range referencedSecrets(active.Definition()) {
+			services[name]++
+		}
+	}
+
+	for _, secret := range secrets {
+		count := services[secret.GetName()]
+
+		unused := 0.0
+		if count == 0 {
+			unused = 1.0
+		}
+
+		ch <- constMetric(
+			c.ReferencedByServices,
+			float64(count),
+			[]string{
+				secret.GetId(),
+				secret.GetName(),
+			}...,
+		)
+		ch <- constMetric(
+			c.Unused,
+			unused,
+			[]string{
+				secret.GetId(),
+				secret.GetName(),
+			}...,
+		)
+	}
 }
 
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *SecretsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.ByRegistry
+	ch <- c.ReferencedByServices
+	ch <- c.Unused
+	ch <- c.Created
+	ch <- c.LastUpdated
+	ch <- c.RotationOverdue
 }
@@ -3,6 +3,7 @@ package collector
 import (
 	"context"
 	"log/slog"
+	"strconv"
 
 	"github.com/DazWilkin/go-probe/probe"
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
@@ -19,11 +20,17 @@ type ServicesCollector struct {
 	ch     chan<- probe.Status
 	logger *slog.Logger
 
-	Up *prometheus.Desc
+	// actives lists the active Deployment of each Service and is shared by collectors
+	actives *ActiveDeployments
+
+	Up        *prometheus.Desc
+	PortInfo  *prometheus.Desc
+	RouteInfo *prometheus.Desc
+	PublicTCP *prometheus.Desc
 }
 
 // NewServicesCollector is a function that creates a new ServicesCollector
-func NewServicesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger) *ServicesCollector {
+func NewServicesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<- probe.Status, l *slog.Logger, actives *ActiveDeployments) *ServicesCollector {
 	subsystem := "services"
 	logger := l.With("collector", subsystem)
 
@@ -33,18 +40,12 @@ func NewServicesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<
 		ch:     ch,
 		logger: logger,
 
-		Up: prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "up"),
-			"1 if the App is up, 0 otherwise",
-			[]string{
-				"id",
-				"app_id",
-				"organization_id",
-				"name",
-				"status",
-			},
-			nil,
-		),
+		actives: actives,
+
+		Up:        serviceUp.Desc(),
+		PortInfo:  servicePortInfo.Desc(),
+		RouteInfo: serviceRouteInfo.Desc(),
+		PublicTCP: servicePublicTCP.Desc(),
 	}
 }
 
@@ -52,8 +53,7 @@ func NewServicesCollector(ctx context.Context, client *koyeb.APIClient, ch chan<
 func (c *ServicesCollector) Collect(ch chan<- prometheus.Metric) {
 	logger := c.logger.With("method", "collect")
 
-	rqst := c.client.ServicesApi.ListServices(c.ctx)
-	resp, _, err := rqst.Execute()
+	services, err := listServices(c.ctx, c.client)
 	if err != nil {
 		msg := "unable to list Services"
 		logger.Error(msg, "err", err)
@@ -76,10 +76,9 @@ func (c *ServicesCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 	c.ch <- status
 
-	for _, service := range resp.Services {
-		ch <- prometheus.MustNewConstMetric(
+	for _, service := range services {
+		ch <- constMetric(
 			c.Up,
-			prometheus.GaugeValue,
 			1.0,
 			[]string{
 				service.GetId(),
@@ -91,9 +90,81 @@ func (c *ServicesCollector) Collect(ch chan<- prometheus.Metric) {
 		)
 	}
 
+	c.collectEndpoints(ch)
 }
 
 // Describe implements Prometheus' Collector interface and is used to describe metrics
 func (c *ServicesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Up
+	ch <- c.PortInfo
+	ch <- c.RouteInfo
+	ch <- c.PublicTCP
+}
+
+// collectEndpoints is a method that collects the ports and public routes of Services' active Deployments
+// A port is public if it is routed (HTTP) or proxied (TCP)
+func (c *ServicesCollector) collectEndpoints(ch chan<- prometheus.Metric) {
+	logger := c.logger.With("method", "collectEndpoints")
+
+	actives, err := c.actives.list(c.ctx)
+	if err != nil {
+		logger.Error("unable to list active Deployments", "err", err)
+		return
+	}
+
+	for _, active := range actives {
+		serviceID := active.Service.GetId()
+		definition := active.Definition()
+
+		// Public ports keyed by port number
+		routed := map[int64]bool{}
+		for _, route := range definition.Routes {
+			routed[route.GetPort()] = true
+
+			ch <- constMetric(
+				c.RouteInfo,
+				1.0,
+				[]string{
+					serviceID,
+					route.GetPath(),
+					strconv.FormatInt(route.GetPort(), 10),
+				}...,
+			)
+		}
+
+		// Proxied (TCP) ports are public regardless of their protocol
+		proxied := map[int64]bool{}
+		for _, proxy := range definition.ProxyPorts {
+			if proxy.GetProtocol() == koyeb.PROXYPORTPROTOCOL_TCP {
+				proxied[proxy.GetPort()] = true
+			}
+		}
+
+		for _, port := range definition.Ports {
+			public := routed[port.GetPort()] || proxied[port.GetPort()]
+
+			ch <- constMetric(
+				c.PortInfo,
+				1.0,
+				[]string{
+					serviceID,
+					strconv.FormatInt(port.GetPort(), 10),
+					port.GetProtocol(),
+					strconv.FormatBool(public),
+				}...,
+			)
+		}
+
+		publicTCP := 0.0
+		if len(proxied) > 0 {
+			publicTCP = 1.0
+		}
+		ch <- constMetric(
+			c.PublicTCP,
+			publicTCP,
+			[]string{
+				serviceID,
+			}...,
+		)
+	}
 }
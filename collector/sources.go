@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+var (
+	// semanticVersion matches semantic version tags capturing their pre-release and build metadata e.g. "v1.2.3-rc.1+20240102"
+	semanticVersion = regexp.MustCompile(`^v?\d+\.\d+\.\d+(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+	// tagDate matches dates (with optional times) in tags e.g. "2024-01-02", "20240102", "2024.01.02-150405"
+	tagDate = regexp.MustCompile(`(?:^|\D)(\d{4})[-.]?(\d{2})[-.]?(\d{2})(?:[-T.]?(\d{2})[-:.]?(\d{2})[-:.]?(\d{2}))?(?:\D|$)`)
+)
+
+// source is the source of a Deployment
+type source struct {
+	sourceType string
+	image      string
+	tag        string
+	repository string
+	branch     string
+	sha        string
+}
+
+// deploymentSource is a function that returns the source of a Deployment
+// Docker image references are split into the image, its tag and its digest (as sha)
+// Unless the definition pins them, the commit (of git sources) and the digest (of Docker sources) are those resolved when the Deployment was provisioned
+func deploymentSource(deployment koyeb.Deployment) source {
+	definition := deployment.GetDefinition()
+	info := deployment.GetProvisioningInfo()
+
+	switch {
+	case definition.Docker != nil:
+		image, tag, digest := parseImage(definition.Docker.GetImage())
+		if digest == "" {
+			_, _, digest = parseImage(info.GetImage())
+		}
+		if digest == "" {
+			digest = info.GetSha()
+		}
+		return source{
+			sourceType: "docker",
+			image:      image,
+			tag:        tag,
+			sha:        digest,
+		}
+	case definition.Git != nil:
+		sha := definition.Git.GetSha()
+		if sha == "" {
+			sha = info.GetSha()
+		}
+		return source{
+			sourceType: "git",
+			image:      info.GetImage(),
+			tag:        definition.Git.GetTag(),
+			repository: definition.Git.GetRepository(),
+			branch:     definition.Git.GetBranch(),
+			sha:        sha,
+		}
+	case definition.Archive != nil:
+		return source{
+			sourceType: "archive",
+		}
+	case definition.Database != nil:
+		return source{
+			sourceType: "database",
+		}
+	default:
+		return source{
+			sourceType: "unknown",
+		}
+	}
+}
+
+// parseImage is a function that splits a Docker image reference into its name, tag and digest
+// e.g. "docker.io/koyeb/demo:v1@sha256:..." is "docker.io/koyeb/demo", "v1" and "sha256:..."
+func parseImage(reference string) (string, string, string) {
+	name, digest, _ := strings.Cut(reference, "@")
+
+	// The tag follows the last colon after the last slash; a colon before the last slash separates a registry's port
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	return name, tag, digest
+}
+
+// tagTime is a function that returns the time encoded in a tag
+// Tags may be dates (with optional times) or semantic versions whose pre-release or build metadata includes a date
+func tagTime(tag string) (time.Time, bool) {
+	// A dotted date e.g. "2024.01.02" is also a semantic version so the whole tag is tried if its metadata isn't a date
+	if m := semanticVersion.FindStringSubmatch(tag); m != nil {
+		if t, ok := parseTagDate(m[1] + "+" + m[2]); ok {
+			return t, true
+		}
+	}
+
+	return parseTagDate(tag)
+}
+
+// parseTagDate is a function that returns the first date (with optional time) in s
+func parseTagDate(s string) (time.Time, bool) {
+	m := tagDate.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	parts := make([]int, 6)
+	for i, s := range m[1:] {
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return time.Time{}, false
+		}
+		parts[i] = n
+	}
+
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.UTC)
+
+	// time.Date normalizes invalid dates e.g. month 13 so reject dates that do not round-trip
+	if t.Year() != parts[0] || int(t.Month()) != parts[1] || t.Day() != parts[2] || t.Hour() != parts[3] || t.Minute() != parts[4] || t.Second() != parts[5] {
+		return time.Time{}, false
+	}
+
+	// Reject numbers that are implausible as dates e.g. build numbers
+	if t.Year() < 2000 || t.After(time.Now().Add(24*time.Hour)) {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
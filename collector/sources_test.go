@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+func TestDeploymentSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment string
+		want       source
+	}{
+		{
+			// A branch deploy is running the commit resolved when it was provisioned
+			name:       "git branch",
+			deployment: `{"definition":{"git":{"repository":"github.com/koyeb/demo","branch":"main"}},"provisioning_info":{"sha":"abc123","image":"registry.koyeb.com/demo@sha256:def"}}`,
+			want: source{
+				sourceType: "git",
+				image:      "registry.koyeb.com/demo@sha256:def",
+				repository: "github.com/koyeb/demo",
+				branch:     "main",
+				sha:        "abc123",
+			},
+		},
+		{
+			name:       "git pinned",
+			deployment: `{"definition":{"git":{"repository":"github.com/koyeb/demo","sha":"pinned"}},"provisioning_info":{"sha":"abc123"}}`,
+			want: source{
+				sourceType: "git",
+				repository: "github.com/koyeb/demo",
+				sha:        "pinned",
+			},
+		},
+		{
+			name:       "docker tag",
+			deployment: `{"definition":{"docker":{"image":"docker.io/koyeb/demo:v1"}},"provisioning_info":{"image":"docker.io/koyeb/demo:v1@sha256:def"}}`,
+			want: source{
+				sourceType: "docker",
+				image:      "docker.io/koyeb/demo",
+				tag:        "v1",
+				sha:        "sha256:def",
+			},
+		},
+		{
+			name:       "docker digest",
+			deployment: `{"definition":{"docker":{"image":"docker.io/koyeb/demo@sha256:abc"}},"provisioning_info":{"image":"docker.io/koyeb/demo@sha256:def"}}`,
+			want: source{
+				sourceType: "docker",
+				image:      "docker.io/koyeb/demo",
+				sha:        "sha256:abc",
+			},
+		},
+		{
+			name:       "database",
+			deployment: `{"definition":{"database":{"neon_postgres":{"pg_version":16}}}}`,
+			want: source{
+				sourceType: "database",
+			},
+		},
+		{
+			name:       "unknown",
+			deployment: `{"definition":{}}`,
+			want: source{
+				sourceType: "unknown",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deployment := koyeb.Deployment{}
+			if err := json.Unmarshal([]byte(test.deployment), &deployment); err != nil {
+				t.Fatal(err)
+			}
+			if got := deploymentSource(deployment); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		reference string
+		image     string
+		tag       string
+		digest    string
+	}{
+		{
+			reference: "koyeb/demo",
+			image:     "koyeb/demo",
+		},
+		{
+			reference: "docker.io/koyeb/demo:v1.2.3",
+			image:     "docker.io/koyeb/demo",
+			tag:       "v1.2.3",
+		},
+		{
+			reference: "registry.example.com:5000/demo",
+			image:     "registry.example.com:5000/demo",
+		},
+		{
+			reference: "registry.example.com:5000/demo:latest@sha256:abc",
+			image:     "registry.example.com:5000/demo",
+			tag:       "latest",
+			digest:    "sha256:abc",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.reference, func(t *testing.T) {
+			image, tag, digest := parseImage(test.reference)
+			if image != test.image || tag != test.tag || digest != test.digest {
+				t.Errorf("got (%q, %q, %q), want (%q, %q, %q)", image, tag, digest, test.image, test.tag, test.digest)
+			}
+		})
+	}
+}
+
+func TestTagTime(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want time.Time
+		ok   bool
+	}{
+		{tag: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{tag: "20240102", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{tag: "2024.01.02-150405", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), ok: true},
+		{tag: "main-20240102T150405", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), ok: true},
+		{tag: "v1.2.3+20240102", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{tag: "1.2.3-rc.20240102", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{tag: "v1.2.3"},
+		{tag: "latest"},
+		{tag: "2024-13-02"},
+		{tag: "12345678"},
+		{tag: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			got, ok := tagTime(test.tag)
+			if ok != test.ok {
+				t.Fatalf("got ok %t, want %t", ok, test.ok)
+			}
+			if ok && !got.Equal(test.want) {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+)
+
+// certificateExpiry is a function that connects to host:443 and returns the NotAfter time of the served (leaf) certificate
+// The certificate is not verified so that the expiry of invalid (including expired) certificates is still reported
+func certificateExpiry(ctx context.Context, host string, timeout time.Duration) (time.Time, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{
+			Timeout: timeout,
+		},
+		Config: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: true, //nolint:gosec // Only the certificate's expiry is read
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, "443"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, errors.New("no peer certificates")
+	}
+
+	return certs[0].NotAfter, nil
+}
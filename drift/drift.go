@@ -0,0 +1,288 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+	"sigs.k8s.io/yaml"
+)
+
+// Missing is the field reported when a desired Service does not exist or has no active Deployment
+const Missing string = "service"
+
+// sensitive are the (list) fields whose values may contain secrets keyed by the field of their items that identifies them
+// The desired and live values of these fields are not reported; only the identifiers of the items that differ are reported
+var sensitive = map[string]string{
+	"env":          "key",
+	"config_files": "path",
+}
+
+// Field is the result of comparing a field of a Service's desired definition with its live definition
+// Keys identifies the items that differ if the field is sensitive, in which case Desired and Live are omitted
+type Field struct {
+	Field   string      `json:"field"`
+	Drifted bool        `json:"drifted"`
+	Desired interface{} `json:"desired,omitempty"`
+	Live    interface{} `json:"live,omitempty"`
+	Keys    []string    `json:"keys,omitempty"`
+}
+
+// Service is the result of comparing a Service's desired definition with its live definition
+type Service struct {
+	App       string  `json:"app"`
+	Service   string  `json:"service"`
+	ServiceID string  `json:"service_id,omitempty"`
+	Source    string  `json:"source"`
+	Drifted   bool    `json:"drifted"`
+	Fields    []Field `json:"fields"`
+}
+
+// Report is the result of comparing every desired definition with the live definitions
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Services  []Service `json:"services"`
+}
+
+// Desired is a desired Deployment definition of a Service of an App and the file from which it was loaded
+type Desired struct {
+	App        string
+	Source     string
+	Definition koyeb.DeploymentDefinition
+	// fields is the definition as loaded so that only the fields that are set are compared
+	fields map[string]interface{}
+}
+
+// Key is a function that returns the key of a Service by its App's name and its name
+// Service names are only unique within an App
+func Key(app, service string) string {
+	return app + "/" + service
+}
+
+// Load is a function that loads the desired Deployment definitions from the YAML and JSON files in the subdirectories of dir
+// Each subdirectory is named after an App and contains the definitions of the App's Services
+// Definitions are keyed by the App's name and their (Service) name (see Key) which must be unique
+func Load(dir string) (map[string]Desired, error) {
+	apps, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := map[string]Desired{}
+	for _, app := range apps {
+		if !app.IsDir() {
+			if definition(app.Name()) {
+				return nil, fmt.Errorf("unable to load %s: definitions must be in a subdirectory named after their App", filepath.Join(dir, app.Name()))
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, app.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !definition(entry.Name()) {
+				continue
+			}
+
+			path := filepath.Join(dir, app.Name(), entry.Name())
+			d, err := load(path)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load %s: %w", path, err)
+			}
+
+			name := d.Definition.GetName()
+			if name == "" {
+				return nil, fmt.Errorf("unable to load %s: definition has no name", path)
+			}
+
+			key := Key(app.Name(), name)
+			if other, ok := desired[key]; ok {
+				return nil, fmt.Errorf("unable to load %s: %q is also defined by %s", path, key, other.Source)
+			}
+
+			d.App = app.Name()
+			desired[key] = d
+		}
+	}
+
+	return desired, nil
+}
+
+// definition is a function that returns whether the file name is that of a (YAML or JSON) definition
+func definition(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// load is a function that loads a desired Deployment definition from a YAML or JSON file
+func load(path string) (Desired, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Desired{}, err
+	}
+
+	// JSON is a subset of YAML
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return Desired{}, err
+	}
+
+	var definition koyeb.DeploymentDefinition
+	if err := strict(j, &definition); err != nil {
+		return Desired{}, err
+	}
+
+	// Round-trip the definition so that fields are represented as they would be by the API
+	fields, err := toMap(definition)
+	if err != nil {
+		return Desired{}, err
+	}
+
+	return Desired{
+		Source:     path,
+		Definition: definition,
+		fields:     fields,
+	}, nil
+}
+
+// Diff is a function that compares the fields set in the desired definition with the live definition
+// Objects are compared field by field; lists are compared without regard to order
+// Fields are identified by their dotted JSON path e.g. "docker.image" and are returned in order
+func Diff(desired Desired, live koyeb.DeploymentDefinition) ([]Field, error) {
+	l, err := toMap(live)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []Field{}
+	compare("", desired.fields, l, &fields)
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	return fields, nil
+}
+
+// compare is a function that compares the desired object with the live object appending the result of each field to fields
+func compare(prefix string, desired, live map[string]interface{}, fields *[]Field) {
+	for key, d := range desired {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		l := live[key]
+
+		dm, dOK := d.(map[string]interface{})
+		lm, lOK := l.(map[string]interface{})
+		if dOK && lOK {
+			compare(path, dm, lm, fields)
+			continue
+		}
+
+		field := Field{
+			Field:   path,
+			Drifted: !equal(d, l),
+			Desired: d,
+			Live:    l,
+		}
+		if id, ok := sensitive[path]; ok {
+			field.Desired = nil
+			field.Live = nil
+			field.Keys = differing(id, d, l)
+		}
+		*fields = append(*fields, field)
+	}
+}
+
+// differing is a function that returns the identifiers (the values of the items' id field) of the items that differ between the desired and live lists
+// Identifiers are returned in order
+func differing(id string, desired, live interface{}) []string {
+	// group is a function that groups a list's items by their identifiers
+	group := func(v interface{}) map[string][]interface{} {
+		result := map[string][]interface{}{}
+		items, _ := v.([]interface{})
+		for _, item := range items {
+			key := ""
+			if m, ok := item.(map[string]interface{}); ok {
+				key = fmt.Sprint(m[id])
+			}
+			result[key] = append(result[key], item)
+		}
+		return result
+	}
+
+	d, l := group(desired), group(live)
+
+	keys := []string{}
+	for key, items := range d {
+		if !equal(items, l[key]) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range l {
+		if _, ok := d[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// equal is a function that compares JSON values treating lists as unordered
+func equal(desired, live interface{}) bool {
+	dl, dOK := desired.([]interface{})
+	ll, lOK := live.([]interface{})
+	if dOK && lOK {
+		return reflect.DeepEqual(canonical(dl), canonical(ll))
+	}
+	return reflect.DeepEqual(desired, live)
+}
+
+// canonical is a function that returns the JSON encodings of the list's items in order
+func canonical(items []interface{}) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		b, _ := json.Marshal(item)
+		result = append(result, string(b))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// toMap is a function that converts a value into its generic JSON representation
+func toMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// strict is a function that decodes JSON rejecting unknown fields so that misspelled fields are not silently ignored
+func strict(b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
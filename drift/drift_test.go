@@ -0,0 +1,218 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+const desiredYAML string = `
+name: web
+regions:
+- fra
+- was
+docker:
+  image: docker.io/koyeb/demo
+  command: ./serve
+`
+
+// write is a function that writes a file in the App's subdirectory of dir
+func write(t *testing.T, dir, app, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, app), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, app, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "shop", "web.yaml", desiredYAML)
+	write(t, dir, "shop", "worker.json", `{"name":"worker","type":"WORKER"}`)
+	write(t, dir, "shop", "README.md", "ignored")
+	// Services with the same name in different Apps are distinct
+	write(t, dir, "blog", "web.yaml", "name: web\ntype: WEB\n")
+	write(t, dir, ".", "README.md", "ignored")
+
+	desired, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(desired) != 3 {
+		t.Fatalf("got %d definitions, want 3", len(desired))
+	}
+	web := desired[Key("shop", "web")]
+	if web.App != "shop" {
+		t.Errorf("got App %q, want %q", web.App, "shop")
+	}
+	if got := web.Definition.Docker.GetImage(); got != "docker.io/koyeb/demo" {
+		t.Errorf("got image %q, want %q", got, "docker.io/koyeb/demo")
+	}
+	worker := desired[Key("shop", "worker")].Definition
+	if got := worker.GetType(); got != koyeb.DEPLOYMENTDEFINITIONTYPE_WORKER {
+		t.Errorf("got type %q, want %q", got, koyeb.DEPLOYMENTDEFINITIONTYPE_WORKER)
+	}
+	if got := desired[Key("blog", "web")].App; got != "blog" {
+		t.Errorf("got App %q, want %q", got, "blog")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "unknown field",
+			files: map[string]string{
+				"shop/web.yaml": "name: web\nimage: docker.io/koyeb/demo\n",
+			},
+		},
+		{
+			name: "no name",
+			files: map[string]string{
+				"shop/web.yaml": "type: WEB\n",
+			},
+		},
+		{
+			name: "duplicate name",
+			files: map[string]string{
+				"shop/a.yaml": "name: web\n",
+				"shop/b.json": `{"name":"web"}`,
+			},
+		},
+		{
+			name: "no App",
+			files: map[string]string{
+				"web.yaml": "name: web\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for path, content := range test.files {
+				write(t, dir, filepath.Dir(path), filepath.Base(path), content)
+			}
+
+			if _, err := Load(dir); err == nil {
+				t.Error("got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "shop", "web.yaml", desiredYAML)
+
+	desired, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := koyeb.DeploymentDefinition{}
+	live.SetName("web")
+	live.SetType(koyeb.DEPLOYMENTDEFINITIONTYPE_WEB)
+	live.SetRegions([]string{"was", "fra"})
+	docker := koyeb.DockerSource{}
+	docker.SetImage("docker.io/koyeb/demo:v2")
+	docker.SetCommand("./serve")
+	live.SetDocker(docker)
+
+	fields, err := Diff(desired[Key("shop", "web")], live)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"docker.command": false,
+		"docker.image":   true,
+		"name":           false,
+		"regions":        false,
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d: %+v", len(fields), len(want), fields)
+	}
+	for _, field := range fields {
+		drifted, ok := want[field.Field]
+		if !ok {
+			t.Errorf("unexpected field %q", field.Field)
+			continue
+		}
+		if field.Drifted != drifted {
+			t.Errorf("field %q: got drifted %t, want %t", field.Field, field.Drifted, drifted)
+		}
+	}
+}
+
+func TestDiffRedacts(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "shop", "web.yaml", `
+name: web
+env:
+- key: PASSWORD
+  value: desired-secret
+- key: REGION
+  value: fra
+- key: TOKEN
+  value: unchanged-secret
+`)
+
+	desired, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := func(key, value string) koyeb.DeploymentEnv {
+		e := koyeb.DeploymentEnv{}
+		e.SetKey(key)
+		e.SetValue(value)
+		return e
+	}
+
+	live := koyeb.DeploymentDefinition{}
+	live.SetName("web")
+	live.SetEnv([]koyeb.DeploymentEnv{
+		env("TOKEN", "unchanged-secret"),
+		env("PASSWORD", "live-secret"),
+		env("DEBUG", "true"),
+	})
+
+	fields, err := Diff(desired[Key("shop", "web")], live)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range fields {
+		if field.Field != "env" {
+			continue
+		}
+
+		if !field.Drifted {
+			t.Error("got not drifted, want drifted")
+		}
+		// Values are not reported
+		if field.Desired != nil || field.Live != nil {
+			t.Errorf("got desired %v and live %v, want neither", field.Desired, field.Live)
+		}
+		// Only the keys of variables that differ (or are missing) are reported
+		want := []string{"DEBUG", "PASSWORD", "REGION"}
+		if len(field.Keys) != len(want) {
+			t.Fatalf("got keys %v, want %v", field.Keys, want)
+		}
+		for i := range want {
+			if field.Keys[i] != want[i] {
+				t.Errorf("got keys %v, want %v", field.Keys, want)
+				break
+			}
+		}
+		return
+	}
+	t.Error("field env not found")
+}
@@ -6,13 +6,14 @@ require (
 	github.com/DazWilkin/go-probe v0.0.0-20250403165833-d2e6a85b4486
 	github.com/koyeb/koyeb-api-client-go v0.0.0-20250610134645-c3eef6519682
 	github.com/prometheus/client_golang v1.22.0
+	github.com/prometheus/client_model v0.6.2
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
-	github.com/prometheus/client_model v0.6.2 // indirect
 	github.com/prometheus/common v0.64.0 // indirect
 	github.com/prometheus/procfs v0.16.1 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"expvar"
 	"flag"
+	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DazWilkin/go-probe/probe"
+	"github.com/DazWilkin/koyeb-exporter/catalog"
 	"github.com/DazWilkin/koyeb-exporter/collector"
+	"github.com/DazWilkin/koyeb-exporter/drift"
+	"github.com/DazWilkin/koyeb-exporter/sink"
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -68,8 +75,91 @@ var (
 var (
 	endpoint    = flag.String("endpoint", ":8080", "The endpoint of the Expoter's HTTP server")
 	metricsPath = flag.String("path", "/metrics", "The path on which Prometheus metrics will be served")
+
+	crashLoopThreshold = flag.Int("crashloop_threshold", 3, "The number of Instance replacements within the window above which a Service is crash-looping")
+	crashLoopWindow    = flag.Duration("crashloop_window", 15*time.Minute, "The window over which Instance replacements are counted")
+
+	domainTLSProbe   = flag.Bool("domain_tls_probe", false, "Probe the TLS certificate served by each custom Domain to determine its expiry")
+	domainTLSTimeout = flag.Duration("domain_tls_timeout", 5*time.Second, "The timeout for each Domain TLS certificate probe")
+
+	secretMaxAge = flag.Duration("secret_max_age", 90*24*time.Hour, "The maximum age of a Secret after which it is overdue rotation (0 disables)")
+
+	exporterCredential = flag.String("exporter_credential", "", "The ID or name of the Credential whose API token the exporter uses; identified by credential_is_exporter_token")
+
+	catalogRefresh = flag.Duration("catalog_refresh", 24*time.Hour, "The interval at which the instance type catalog is refreshed from the Koyeb API; 0 refreshes it once at startup")
+
+	activeDeploymentsTTL = flag.Duration("active_deployments_ttl", 30*time.Second, "The duration for which the active Deployments of Services are retained and shared by collectors")
+
+	budgetOrganization = flag.Float64("budget_organization", 0, "The monthly budget of the Organization (0 disables)")
+	budgetApps         = flag.String("budget_apps", "", "The monthly budgets of Apps as a comma-separated list of {app}={amount} where {app} is the App's ID or name")
+
+	eventLog       = flag.Bool("event_log", false, "Log each new Koyeb event as a structured record on stdout")
+	eventFile      = flag.String("event_file", "", "The path of a file to which each new Koyeb event is appended as a structured record")
+	eventWebhook   = flag.String("event_webhook", "", "The URL of an HTTP endpoint to which each new Koyeb event is POSTed as JSON")
+	eventSyslog    = flag.String("event_syslog", "", "The syslog server to which each new Koyeb event is sent ('local' or udp://host:port or tcp://host:port)")
+	eventState     = flag.String("event_state", "", "The path of a file in which the IDs of forwarded Koyeb events are recorded to avoid duplicates across restarts")
+	eventRetention = flag.Duration("event_retention", 7*24*time.Hour, "The duration for which the IDs of forwarded Koyeb events are recorded")
+
+	httpProbe         = flag.Bool("http_probe", false, "Probe the public routes of Services on their App's Domains")
+	httpProbeInterval = flag.Duration("http_probe_interval", time.Minute, "The interval at which the public routes of Services are probed; must be positive")
+	httpProbeTimeout  = flag.Duration("http_probe_timeout", 10*time.Second, "The timeout for each probe of a public route")
+
+	logLineBudget = flag.Int("log_line_budget", 10000, "The maximum number of runtime log lines read per scrape; lines beyond the budget are skipped")
+
+	driftDir = flag.String("drift_dir", "", "The path of a directory of subdirectories (one per App) of desired Deployment definitions (YAML or JSON) against which Services' active Deployments are compared")
 )
 
+// logRules is the list of log rules set by (repeated) --log_rule flags
+var logRules ruleFlags
+
+// buildRules is the list of build rules set by (repeated) --build_rule flags
+var buildRules buildRuleFlags
+
+func init() {
+	flag.Var(&logRules, "log_rule", "A rule ({name}={regex}) against which Services' runtime log lines are matched (repeatable)")
+	flag.Var(&buildRules, "build_rule", "A rule ({reason}={regex}) that classifies failed builds by their build log; precedes the default rules (repeatable)")
+}
+
+// ruleFlags implements flag.Value and is used to parse repeated --log_rule flags
+type ruleFlags []collector.LogRule
+
+func (r *ruleFlags) String() string {
+	names := make([]string, 0, len(*r))
+	for _, rule := range *r {
+		names = append(names, rule.Name+"="+rule.Pattern.String())
+	}
+	return strings.Join(names, ",")
+}
+
+func (r *ruleFlags) Set(s string) error {
+	rule, err := collector.ParseLogRule(s)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+// buildRuleFlags implements flag.Value and is used to parse repeated --build_rule flags
+type buildRuleFlags []collector.BuildRule
+
+func (r *buildRuleFlags) String() string {
+	reasons := make([]string, 0, len(*r))
+	for _, rule := range *r {
+		reasons = append(reasons, rule.Reason+"="+rule.Pattern.String())
+	}
+	return strings.Join(reasons, ",")
+}
+
+func (r *buildRuleFlags) Set(s string) error {
+	rule, err := collector.ParseBuildRule(s)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
 type Content struct {
 	Name               string
 	MetricsPath        string
@@ -96,6 +186,81 @@ func root(content Content) http.HandlerFunc {
 	}
 }
 
+// driftReport is a function that serves the most recent drift report as JSON
+func driftReport(c *collector.DriftCollector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.Report()); err != nil {
+			slog.Error("unable to write drift report")
+		}
+	}
+}
+
+// parseBudgets is a function that parses a comma-separated list of {key}={amount} budgets
+func parseBudgets(s string) (map[string]float64, error) {
+	budgets := map[string]float64{}
+	if s == "" {
+		return budgets, nil
+	}
+
+	for _, budget := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(budget, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid budget: %q", budget)
+		}
+
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget amount: %q", budget)
+		}
+
+		budgets[key] = amount
+	}
+
+	return budgets, nil
+}
+
+// newEventHandler is a function that creates a Forwarder for the configured event sinks
+// Returns nil if no event sinks are configured
+func newEventHandler(logger *slog.Logger) (*sink.Forwarder, error) {
+	sinks := []sink.Sink{}
+	if *eventLog {
+		sinks = append(sinks, sink.NewLogSink(os.Stdout))
+	}
+	if *eventFile != "" {
+		s, err := sink.NewFileSink(*eventFile)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if *eventWebhook != "" {
+		sinks = append(sinks, sink.NewWebhookSink(*eventWebhook, 10*time.Second))
+	}
+	if *eventSyslog != "" {
+		s, err := sink.NewSyslogSink(*eventSyslog)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	var state *sink.State
+	if *eventState != "" {
+		s, err := sink.NewState(*eventState, *eventRetention)
+		if err != nil {
+			return nil, err
+		}
+		state = s
+	}
+
+	return sink.NewForwarder(sinks, state, logger), nil
+}
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
@@ -130,13 +295,78 @@ func main() {
 	client := koyeb.NewAPIClient(cfg)
 	ctx = context.WithValue(ctx, koyeb.ContextAccessToken, token)
 
+	// The instance type catalog is refreshed in the background and falls back to the bundled catalog
+	instanceTypes, err := catalog.New(client, logger)
+	if err != nil {
+		logger.Error("unable to create instance type catalog", "err", err)
+		return
+	}
+	go instanceTypes.Run(ctx, *catalogRefresh)
+
+	// Services' active Deployments are listed once and shared by the collectors that need them
+	actives := collector.NewActiveDeployments(client, logger, *activeDeploymentsTTL)
+
+	appBudgets, err := parseBudgets(*budgetApps)
+	if err != nil {
+		logger.Error("unable to parse App budgets", "err", err)
+		return
+	}
+
+	// EventsCollector's handler must be a nil interface (not a nil *Forwarder) when no event sinks are configured
+	var handler collector.EventHandler
+	forwarder, err := newEventHandler(logger)
+	if err != nil {
+		logger.Error("unable to create event sinks", "err", err)
+		return
+	}
+	if forwarder != nil {
+		defer forwarder.Close()
+		go forwarder.Run(ctx)
+		handler = forwarder
+	}
+
+	// Drift detection is only enabled when a directory of desired Deployment definitions is configured
+	var driftCollector *collector.DriftCollector
+	if *driftDir != "" {
+		desired, err := drift.Load(*driftDir)
+		if err != nil {
+			logger.Error("unable to load desired Deployment definitions", "err", err)
+			return
+		}
+		driftCollector = collector.NewDriftCollector(ctx, client, ch, logger, actives, desired)
+	}
+
+	// Probing is only enabled when requested as it issues requests against the Services' public routes
+	var httpProbesCollector *collector.HTTPProbesCollector
+	if *httpProbe {
+		if *httpProbeInterval <= 0 {
+			logger.Error("invalid HTTP probe interval", "interval", *httpProbeInterval)
+			return
+		}
+		httpProbesCollector = collector.NewHTTPProbesCollector(ctx, client, ch, logger, actives, *httpProbeTimeout)
+		go httpProbesCollector.Run(ctx, *httpProbeInterval)
+	}
+
+	// Log lines are only read when rules are configured
+	var logsCollector *collector.LogsCollector
+	if len(logRules) > 0 {
+		if *logLineBudget <= 0 {
+			logger.Error("invalid log line budget", "budget", *logLineBudget)
+			return
+		}
+		logsCollector = collector.NewLogsCollector(ctx, client, ch, logger, logRules, *logLineBudget)
+	}
+
 	registry := prometheus.NewRegistry()
 
-	// Register collectors
-	for _, c := range []struct {
+	// registration is a collector and the name by which it is logged
+	type registration struct {
 		name      string
 		collector prometheus.Collector
-	}{
+	}
+
+	// Register collectors
+	collectors := []registration{
 		{
 			"exporter",
 			collector.NewExporterCollector(OSVersion, GoVersion, GitCommit, StartTime),
@@ -145,31 +375,80 @@ func main() {
 			"apps",
 			collector.NewAppsCollector(ctx, client, ch, logger),
 		},
+		{
+			"billing",
+			collector.NewBillingCollector(ctx, client, ch, logger),
+		},
+		{
+			"budget",
+			collector.NewBudgetCollector(ctx, client, ch, logger, instanceTypes, *budgetOrganization, appBudgets),
+		},
+		{
+			"builds",
+			collector.NewBuildsCollector(ctx, client, ch, logger, append(buildRules, collector.DefaultBuildRules...)),
+		},
 		{
 			"credentials",
-			collector.NewCredentialsCollector(ctx, client, ch, logger),
+			collector.NewCredentialsCollector(ctx, client, ch, logger, *exporterCredential),
 		},
 		{
 			"deployments",
-			collector.NewDeploymentsCollector(ctx, client, ch, logger),
+			collector.NewDeploymentsCollector(ctx, client, ch, logger, actives),
 		},
 		{
 			"domains",
-			collector.NewDomainsCollector(ctx, client, ch, logger),
+			collector.NewDomainsCollector(ctx, client, ch, logger, actives, *domainTLSProbe, *domainTLSTimeout),
+		},
+		{
+			"env",
+			collector.NewEnvCollector(ctx, client, ch, logger, actives),
+		},
+		{
+			"events",
+			collector.NewEventsCollector(ctx, client, ch, logger, handler),
+		},
+		{
+			"health_checks",
+			collector.NewHealthChecksCollector(ctx, client, ch, logger, actives),
 		},
 		{
 			"instances",
-			collector.NewInstancesCollector(ctx, client, ch, logger),
+			collector.NewInstancesCollector(ctx, client, ch, logger, instanceTypes, *crashLoopThreshold, *crashLoopWindow),
+		},
+		{
+			"organization",
+			collector.NewOrganizationCollector(ctx, client, ch, logger),
+		},
+		{
+			"quotas",
+			collector.NewQuotasCollector(ctx, client, ch, logger, instanceTypes),
+		},
+		{
+			"regional_deployments",
+			collector.NewRegionalDeploymentsCollector(ctx, client, ch, logger, actives),
 		},
 		{
 			"secrets",
-			collector.NewSecretsCollector(ctx, client, ch, logger),
+			collector.NewSecretsCollector(ctx, client, ch, logger, actives, *secretMaxAge),
 		},
 		{
 			"services",
-			collector.NewServicesCollector(ctx, client, ch, logger),
+			collector.NewServicesCollector(ctx, client, ch, logger, actives),
 		},
-	} {
+	}
+
+	// Optional collectors are only registered when enabled
+	if driftCollector != nil {
+		collectors = append(collectors, registration{"drift", driftCollector})
+	}
+	if httpProbesCollector != nil {
+		collectors = append(collectors, registration{"http_probes", httpProbesCollector})
+	}
+	if logsCollector != nil {
+		collectors = append(collectors, registration{"logs", logsCollector})
+	}
+
+	for _, c := range collectors {
 		if err := registry.Register(c.collector); err != nil {
 			logger.Error("failed to register collector",
 				"collector", c.name,
@@ -195,7 +474,14 @@ func main() {
 	mux.Handle("/robots.txt", http.HandlerFunc(robots))
 
 	mux.Handle("/varz", expvar.Handler())
-	mux.Handle(*metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	if driftCollector != nil {
+		mux.Handle("/drift", driftReport(driftCollector))
+	}
+	// OpenMetrics is negotiated (by the Accept header) so that counters' created timestamps and exemplars are exposed
+	mux.Handle(*metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		EnableOpenMetrics:                   true,
+		EnableOpenMetricsTextCreatedSamples: true,
+	}))
 
 	logger.Info("Server starting",
 		"endpoint", *endpoint,
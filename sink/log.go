@@ -0,0 +1,64 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+)
+
+// Ensure that LogSink implements Sink
+var _ Sink = (*LogSink)(nil)
+
+// LogSink emits each event as a structured (JSON) slog record on a dedicated logger
+type LogSink struct {
+	logger *slog.Logger
+	closer io.Closer
+}
+
+// NewLogSink is a function that creates a new LogSink that writes to w
+func NewLogSink(w io.Writer) *LogSink {
+	logger := slog.New(slog.NewJSONHandler(w, nil)).With("logger", "events")
+	return &LogSink{
+		logger: logger,
+	}
+}
+
+// NewFileSink is a function that creates a new LogSink that appends to the file at path
+func NewFileSink(path string) (*LogSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewLogSink(f)
+	s.closer = f
+	return s, nil
+}
+
+// Send implements Sink and is used to log an event
+func (s *LogSink) Send(ctx context.Context, event types.Event) error {
+	s.logger.LogAttrs(ctx, slog.LevelInfo, "event",
+		slog.String("id", event.ID),
+		slog.Time("when", event.When),
+		slog.String("type", event.Type),
+		slog.String("resource_kind", string(event.ResourceKind)),
+		slog.String("resource_id", event.ResourceID),
+		slog.String("organization_id", event.OrganizationID),
+		slog.String("app_id", event.AppID),
+		slog.String("service_id", event.ServiceID),
+		slog.String("message", event.Message),
+		slog.Any("metadata", event.Metadata),
+	)
+	return nil
+}
+
+// Close implements Sink and is used to close the underlying file (if any)
+func (s *LogSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
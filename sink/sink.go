@@ -0,0 +1,153 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+)
+
+// Sink is implemented by destinations to which Koyeb events are forwarded
+type Sink interface {
+	// Send forwards a single event
+	Send(ctx context.Context, event types.Event) error
+	// Close releases any resources held by the Sink
+	Close() error
+}
+
+// queueSize is the number of batches of events that may be queued to be forwarded
+const queueSize int = 100
+
+// sendAttempts is the number of times that an event is sent to a Sink before it is abandoned
+// sendBackoff is the delay before the first retry; the delay doubles with each retry
+const (
+	sendAttempts int           = 3
+	sendBackoff  time.Duration = time.Second
+)
+
+// Forwarder forwards new Koyeb events to one or more Sinks
+// Events are queued by Handle and forwarded by Run so that slow Sinks do not block scrapes
+// Events that have previously been forwarded (by ID) are not forwarded again
+type Forwarder struct {
+	sinks  []Sink
+	state  *State
+	logger *slog.Logger
+
+	// backoff is the delay before the first retry of a failed send
+	backoff time.Duration
+
+	queue chan []types.Event
+}
+
+// NewForwarder is a function that creates a new Forwarder
+// state may be nil in which case events are not deduplicated across restarts
+func NewForwarder(sinks []Sink, state *State, l *slog.Logger) *Forwarder {
+	logger := l.With("forwarder", "events")
+	return &Forwarder{
+		sinks:  sinks,
+		state:  state,
+		logger: logger,
+
+		backoff: sendBackoff,
+
+		queue: make(chan []types.Event, queueSize),
+	}
+}
+
+// Handle is a method that queues events to be forwarded by Run
+// Handle does not block: when the queue is full, the events are dropped
+func (f *Forwarder) Handle(ctx context.Context, events []types.Event) {
+	logger := f.logger.With("method", "handle")
+
+	select {
+	case f.queue <- events:
+	default:
+		logger.Error("unable to queue events; dropping them", "events", len(events))
+	}
+}
+
+// Run is a method that forwards queued events until the context is done
+func (f *Forwarder) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case events := <-f.queue:
+			f.forward(ctx, events)
+		}
+	}
+}
+
+// forward is a method that forwards events to every Sink
+// Sends that fail are retried (with backoff) to the failing Sinks only so that other Sinks do not receive duplicates
+// Events are only marked as forwarded once every Sink has accepted them
+func (f *Forwarder) forward(ctx context.Context, events []types.Event) {
+	logger := f.logger.With("method", "forward")
+
+	forwarded := 0
+	for _, event := range events {
+		if f.state != nil && f.state.Seen(event.ID) {
+			continue
+		}
+
+		sent := true
+		for _, s := range f.sinks {
+			if err := f.send(ctx, s, event); err != nil {
+				logger.Error("unable to forward event",
+					"id", event.ID,
+					"err", err,
+				)
+				sent = false
+			}
+		}
+		if !sent {
+			continue
+		}
+
+		if f.state != nil {
+			f.state.Add(event.ID, event.When)
+		}
+		forwarded++
+	}
+
+	if f.state != nil && forwarded > 0 {
+		if err := f.state.Save(); err != nil {
+			logger.Error("unable to save state", "err", err)
+		}
+	}
+}
+
+// send is a method that sends an event to a Sink, retrying with exponential backoff
+// Returns the last error if every attempt fails or the context is done
+func (f *Forwarder) send(ctx context.Context, s Sink, event types.Event) error {
+	backoff := f.backoff
+
+	var err error
+	for attempt := range sendAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if err = s.Send(ctx, event); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Close is a method that closes every Sink
+func (f *Forwarder) Close() error {
+	errs := []error{}
+	for _, s := range f.sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,247 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+)
+
+// testSink is a Sink that records the IDs of the events that it is sent
+// The first failures sends fail and, if err is set, every send fails
+type testSink struct {
+	mu       sync.Mutex
+	ids      []string
+	err      error
+	failures int
+	block    chan struct{}
+	closed   bool
+}
+
+// Send implements Sink
+func (s *testSink) Send(ctx context.Context, event types.Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids = append(s.ids, event.ID)
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	return s.err
+}
+
+// Close implements Sink
+func (s *testSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *testSink) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.ids...)
+}
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestEvents(ids ...string) []types.Event {
+	when := time.Now()
+
+	events := make([]types.Event, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, types.Event{
+			ID:   id,
+			When: when,
+		})
+	}
+	return events
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestForwarderFilters(t *testing.T) {
+	ctx := context.Background()
+
+	state, err := NewState(filepath.Join(t.TempDir(), "state.json"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Add("e1", time.Now())
+
+	s := &testSink{}
+	f := NewForwarder([]Sink{s}, state, newTestLogger())
+
+	// e1 has previously been forwarded
+	f.forward(ctx, newTestEvents("e1", "e2"))
+	// e2 has now been forwarded
+	f.forward(ctx, newTestEvents("e2", "e3"))
+
+	if got, want := s.sent(), []string{"e2", "e3"}; !equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The forwarded events are persisted
+	state, err = NewState(state.path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if !state.Seen(id) {
+			t.Errorf("event %q not recorded as forwarded", id)
+		}
+	}
+}
+
+func TestForwarderRetries(t *testing.T) {
+	ctx := context.Background()
+
+	state, err := NewState(filepath.Join(t.TempDir(), "state.json"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		sink *testSink
+		sent []string
+		// forwarded is whether the event is recorded as forwarded
+		forwarded bool
+	}{
+		{
+			// A transient failure is retried
+			name: "transient",
+			sink: &testSink{
+				failures: sendAttempts - 1,
+			},
+			sent:      []string{"e1", "e1", "e1"},
+			forwarded: true,
+		},
+		{
+			// An event that is never accepted is not recorded as forwarded
+			name: "failing",
+			sink: &testSink{
+				err: errors.New("unavailable"),
+			},
+			sent:      []string{"e2", "e2", "e2"},
+			forwarded: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id := test.sent[0]
+
+			// Only the failing Sink is retried
+			ok := &testSink{}
+			f := NewForwarder([]Sink{ok, test.sink}, state, newTestLogger())
+			f.backoff = time.Millisecond
+
+			f.forward(ctx, newTestEvents(id))
+
+			if got, want := ok.sent(), []string{id}; !equal(got, want) {
+				t.Errorf("ok: got %v, want %v", got, want)
+			}
+			if got := test.sink.sent(); !equal(got, test.sent) {
+				t.Errorf("got %v, want %v", got, test.sent)
+			}
+			if got := state.Seen(id); got != test.forwarded {
+				t.Errorf("got forwarded %t, want %t", got, test.forwarded)
+			}
+		})
+	}
+}
+
+func TestForwarderWithoutState(t *testing.T) {
+	s := &testSink{}
+	f := NewForwarder([]Sink{s}, nil, newTestLogger())
+
+	// Without State, events are not deduplicated
+	f.forward(context.Background(), newTestEvents("e1"))
+	f.forward(context.Background(), newTestEvents("e1"))
+
+	if got, want := s.sent(), []string{"e1", "e1"}; !equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestForwarderRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &testSink{
+		block: make(chan struct{}),
+	}
+	f := NewForwarder([]Sink{s}, nil, newTestLogger())
+	go f.Run(ctx)
+
+	// Wait for Run to receive the first batch (and block in the Sink)
+	f.Handle(ctx, newTestEvents("e"))
+	deadline := time.After(5 * time.Second)
+	for len(f.queue) > 0 {
+		select {
+		case <-deadline:
+			t.Fatal("queued events were not received")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// Handle does not block while the Sink is blocked and drops events when the queue is full
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+1; i++ {
+			f.Handle(ctx, newTestEvents("e"))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handle blocked")
+	}
+
+	// Unblock the Sink and wait for the queued events to be forwarded
+	close(s.block)
+
+	// The first batch and queueSize queued batches are forwarded and the remaining batch is dropped
+	for len(s.sent()) < queueSize+1 {
+		select {
+		case <-deadline:
+			t.Fatalf("got %d events, want %d", len(s.sent()), queueSize+1)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestForwarderClose(t *testing.T) {
+	s := &testSink{}
+	f := NewForwarder([]Sink{s}, nil, newTestLogger())
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.closed {
+		t.Error("Sink not closed")
+	}
+}
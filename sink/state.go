@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State records the IDs of forwarded events in a local file so that events are not forwarded again after a restart
+// IDs of events older than retention are discarded when the State is saved
+type State struct {
+	path      string
+	retention time.Duration
+
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+// NewState is a function that creates a new State loaded from the file at path
+// A missing file is treated as an empty State
+func NewState(path string, retention time.Duration) (*State, error) {
+	s := &State{
+		path:      path,
+		retention: retention,
+		ids:       map[string]time.Time{},
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &s.ids); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Seen is a method that returns whether the event ID has been forwarded
+func (s *State) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.ids[id]
+	return ok
+}
+
+// Add is a method that records the event ID as forwarded
+func (s *State) Add(id string, when time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids[id] = when
+}
+
+// Save is a method that writes the State to its file
+// The file is replaced atomically so that a crash does not corrupt it
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.retention)
+	for id, when := range s.ids {
+		if when.Before(cutoff) {
+			delete(s.ids, id)
+		}
+	}
+
+	b, err := json.Marshal(s.ids)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// A missing file is an empty State
+	s, err := NewState(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Seen("e1") {
+		t.Error("got e1 seen, want unseen")
+	}
+
+	now := time.Now()
+	s.Add("e1", now)
+	s.Add("e2", now.Add(-2*time.Hour))
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewState(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Seen("e1") {
+		t.Error("got e1 unseen, want seen")
+	}
+	// IDs older than the retention are discarded when saved
+	if s.Seen("e2") {
+		t.Error("got e2 seen, want unseen")
+	}
+
+	// The file is replaced atomically and no temporary files remain
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want 1", len(entries))
+	}
+}
+
+func TestStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewState(path, time.Hour); err == nil {
+		t.Error("got nil error, want error")
+	}
+}
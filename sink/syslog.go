@@ -0,0 +1,32 @@
+package sink
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+)
+
+// NewSyslogSink is a function that creates a new LogSink that writes to syslog
+// address is either "local" (the local syslog daemon) or a URL of the form udp://host:port or tcp://host:port
+func NewSyslogSink(address string) (*LogSink, error) {
+	network, raddr := "", ""
+	if address != "local" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "udp" && u.Scheme != "tcp" {
+			return nil, fmt.Errorf("unsupported syslog network: %q", u.Scheme)
+		}
+		network, raddr = u.Scheme, u.Host
+	}
+
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, "koyeb-exporter")
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewLogSink(w)
+	s.closer = w
+	return s, nil
+}
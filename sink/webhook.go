@@ -0,0 +1,62 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+)
+
+// Ensure that WebhookSink implements Sink
+var _ Sink = (*WebhookSink)(nil)
+
+// WebhookSink POSTs each event as JSON to an HTTP endpoint
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink is a function that creates a new WebhookSink
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url: url,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Send implements Sink and is used to POST an event
+func (s *WebhookSink) Send(ctx context.Context, event types.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	rqst, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	rqst.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(rqst)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Close implements Sink
+func (s *WebhookSink) Close() error {
+	return nil
+}
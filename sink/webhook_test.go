@@ -0,0 +1,104 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DazWilkin/koyeb-exporter/types"
+)
+
+func TestWebhookSink(t *testing.T) {
+	want := types.Event{
+		ID:           "e1",
+		When:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Type:         "service.created",
+		ResourceKind: types.ServiceResource,
+		ResourceID:   "s1",
+		AppID:        "a1",
+		ServiceID:    "s1",
+		Message:      "Service created",
+		Metadata: map[string]interface{}{
+			"reason": "test",
+		},
+	}
+
+	received := make(chan types.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got method %q, want %q", r.Method, http.MethodPost)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got Content-Type %q, want %q", got, "application/json")
+		}
+
+		event := types.Event{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	s := NewWebhookSink(server.URL, time.Second)
+	if err := s.Send(context.Background(), want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if got.ID != want.ID || !got.When.Equal(want.When) || got.Type != want.Type || got.ResourceKind != want.ResourceKind {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.AppID != want.AppID || got.ServiceID != want.ServiceID || got.Message != want.Message {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.Metadata["reason"] != "test" {
+		t.Errorf("got metadata %v, want %v", got.Metadata, want.Metadata)
+	}
+}
+
+func TestWebhookSinkErrors(t *testing.T) {
+	event := types.Event{
+		ID: "e1",
+	}
+
+	t.Run("status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		s := NewWebhookSink(server.URL, time.Second)
+		if err := s.Send(context.Background(), event); err == nil {
+			t.Error("got nil error, want error")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		s := NewWebhookSink(server.URL, 10*time.Millisecond)
+		if err := s.Send(context.Background(), event); err == nil {
+			t.Error("got nil error, want error")
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		s := NewWebhookSink(url, time.Second)
+		if err := s.Send(context.Background(), event); err == nil {
+			t.Error("got nil error, want error")
+		}
+	})
+}
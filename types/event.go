@@ -0,0 +1,105 @@
+package types
+
+import (
+	"time"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+// ResourceKind identifies the kind of Koyeb resource that an Event concerns
+type ResourceKind string
+
+const (
+	ServiceResource      ResourceKind = "service"
+	DeploymentResource   ResourceKind = "deployment"
+	InstanceResource     ResourceKind = "instance"
+	OrganizationResource ResourceKind = "organization"
+)
+
+// Event is a normalized representation of Koyeb's Service, Deployment and Instance events and Organization activities
+// AppID and ServiceID are not provided by every kind of event and may be empty
+type Event struct {
+	ID             string                 `json:"id"`
+	When           time.Time              `json:"when"`
+	Type           string                 `json:"type"`
+	ResourceKind   ResourceKind           `json:"resource_kind"`
+	ResourceID     string                 `json:"resource_id"`
+	OrganizationID string                 `json:"organization_id,omitempty"`
+	AppID          string                 `json:"app_id,omitempty"`
+	ServiceID      string                 `json:"service_id,omitempty"`
+	Message        string                 `json:"message,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FromServiceEvent is a function that converts a Koyeb ServiceEvent into an Event
+func FromServiceEvent(e koyeb.ServiceEvent) Event {
+	return Event{
+		ID:             e.GetId(),
+		When:           e.GetWhen(),
+		Type:           e.GetType(),
+		ResourceKind:   ServiceResource,
+		ResourceID:     e.GetServiceId(),
+		OrganizationID: e.GetOrganizationId(),
+		ServiceID:      e.GetServiceId(),
+		Message:        e.GetMessage(),
+		Metadata:       e.Metadata,
+	}
+}
+
+// FromDeploymentEvent is a function that converts a Koyeb DeploymentEvent into an Event
+func FromDeploymentEvent(e koyeb.DeploymentEvent) Event {
+	return Event{
+		ID:             e.GetId(),
+		When:           e.GetWhen(),
+		Type:           e.GetType(),
+		ResourceKind:   DeploymentResource,
+		ResourceID:     e.GetDeploymentId(),
+		OrganizationID: e.GetOrganizationId(),
+		Message:        e.GetMessage(),
+		Metadata:       e.Metadata,
+	}
+}
+
+// FromInstanceEvent is a function that converts a Koyeb InstanceEvent into an Event
+func FromInstanceEvent(e koyeb.InstanceEvent) Event {
+	return Event{
+		ID:             e.GetId(),
+		When:           e.GetWhen(),
+		Type:           e.GetType(),
+		ResourceKind:   InstanceResource,
+		ResourceID:     e.GetInstanceId(),
+		OrganizationID: e.GetOrganizationId(),
+		Message:        e.GetMessage(),
+		Metadata:       e.Metadata,
+	}
+}
+
+// FromActivity is a function that converts a Koyeb (Organization) Activity into an Event
+// The Event's Type is the Activity's verb qualified by the type of the Activity's object e.g. "service.created"
+func FromActivity(a koyeb.Activity) Event {
+	object := a.GetObject()
+
+	t := a.GetVerb()
+	if objectType := object.GetType(); objectType != "" {
+		t = objectType + "." + t
+	}
+
+	e := Event{
+		ID:           a.GetId(),
+		When:         a.GetCreatedAt(),
+		Type:         t,
+		ResourceKind: OrganizationResource,
+		ResourceID:   object.GetId(),
+		Message:      object.GetName(),
+		Metadata:     a.Metadata,
+	}
+
+	switch object.GetType() {
+	case "app":
+		e.AppID = object.GetId()
+	case "service":
+		e.ServiceID = object.GetId()
+	}
+
+	return e
+}
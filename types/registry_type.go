@@ -1,6 +1,9 @@
 package types
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
 )
 
@@ -15,8 +18,24 @@ const (
 	GitHub       RegistryType = 5
 	Gitlab       RegistryType = 6
 	Private      RegistryType = 7
+	// Simple and Database are not registries but are the kinds of non-registry Secrets
+	Simple   RegistryType = 8
+	Database RegistryType = 9
 )
 
+// RegistryTypes is the list of every defined RegistryType
+var RegistryTypes = []RegistryType{
+	Azure,
+	DigitalOcean,
+	DockerHub,
+	Google,
+	GitHub,
+	Gitlab,
+	Private,
+	Simple,
+	Database,
+}
+
 // GetRegistryType is a function that determines the underlying registry type
 func GetRegistryType(secret koyeb.Secret) RegistryType {
 	if secret.AzureContainerRegistry != nil {
@@ -40,6 +59,12 @@ func GetRegistryType(secret koyeb.Secret) RegistryType {
 	if secret.PrivateRegistry != nil {
 		return Private
 	}
+	if secret.DatabaseRolePassword != nil {
+		return Database
+	}
+	if secret.GetType() == koyeb.SECRETTYPE_SIMPLE {
+		return Simple
+	}
 	return Undefined
 }
 
@@ -51,6 +76,8 @@ func (x RegistryType) String() string {
 		return "Azure"
 	case DigitalOcean:
 		return "DigitalOcean"
+	case DockerHub:
+		return "DockerHub"
 	case Google:
 		return "GCR"
 	case GitHub:
@@ -59,7 +86,38 @@ func (x RegistryType) String() string {
 		return "Gitlab"
 	case Private:
 		return "private"
+	case Simple:
+		return "simple"
+	case Database:
+		return "database"
 	default:
 		return ""
 	}
 }
+
+// MarshalText implements encoding.TextMarshaler and is used to represent a RegistryType by its name
+func (x RegistryType) MarshalText() ([]byte, error) {
+	if x != Undefined && x.String() == "" {
+		return nil, fmt.Errorf("invalid registry type: %d", x)
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and is used to parse a RegistryType from its (case-insensitive) name
+// An empty name is Undefined
+func (x *RegistryType) UnmarshalText(text []byte) error {
+	name := string(text)
+	if name == "" {
+		*x = Undefined
+		return nil
+	}
+
+	for _, t := range RegistryTypes {
+		if strings.EqualFold(name, t.String()) {
+			*x = t
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown registry type: %q", name)
+}
@@ -0,0 +1,199 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/koyeb/koyeb-api-client-go/api/v1/koyeb"
+)
+
+func TestGetRegistryType(t *testing.T) {
+	simple := koyeb.SECRETTYPE_SIMPLE
+	registry := koyeb.SECRETTYPE_REGISTRY
+	managed := koyeb.SECRETTYPE_MANAGED
+
+	tests := []struct {
+		name   string
+		secret koyeb.Secret
+		want   RegistryType
+	}{
+		{
+			name:   "empty",
+			secret: koyeb.Secret{},
+			want:   Undefined,
+		},
+		{
+			name: "azure",
+			secret: koyeb.Secret{
+				Type:                   &registry,
+				AzureContainerRegistry: &koyeb.AzureContainerRegistryConfiguration{},
+			},
+			want: Azure,
+		},
+		{
+			name: "digitalocean",
+			secret: koyeb.Secret{
+				Type:                 &registry,
+				DigitalOceanRegistry: &koyeb.DigitalOceanRegistryConfiguration{},
+			},
+			want: DigitalOcean,
+		},
+		{
+			name: "dockerhub",
+			secret: koyeb.Secret{
+				Type:              &registry,
+				DockerHubRegistry: &koyeb.DockerHubRegistryConfiguration{},
+			},
+			want: DockerHub,
+		},
+		{
+			name: "google",
+			secret: koyeb.Secret{
+				Type:                 &registry,
+				GcpContainerRegistry: &koyeb.GCPContainerRegistryConfiguration{},
+			},
+			want: Google,
+		},
+		{
+			name: "github",
+			secret: koyeb.Secret{
+				Type:           &registry,
+				GithubRegistry: &koyeb.GitHubRegistryConfiguration{},
+			},
+			want: GitHub,
+		},
+		{
+			name: "gitlab",
+			secret: koyeb.Secret{
+				Type:           &registry,
+				GitlabRegistry: &koyeb.GitLabRegistryConfiguration{},
+			},
+			want: Gitlab,
+		},
+		{
+			name: "private",
+			secret: koyeb.Secret{
+				Type:            &registry,
+				PrivateRegistry: &koyeb.PrivateRegistryConfiguration{},
+			},
+			want: Private,
+		},
+		{
+			name: "simple",
+			secret: koyeb.Secret{
+				Type: &simple,
+			},
+			want: Simple,
+		},
+		{
+			name: "database",
+			secret: koyeb.Secret{
+				Type:                 &managed,
+				DatabaseRolePassword: &koyeb.DatabaseRolePassword{},
+			},
+			want: Database,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := GetRegistryType(test.secret)
+			if got != test.want {
+				t.Errorf("got: %v; want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegistryTypeString(t *testing.T) {
+	tests := []struct {
+		registryType RegistryType
+		want         string
+	}{
+		{Undefined, ""},
+		{Azure, "Azure"},
+		{DigitalOcean, "DigitalOcean"},
+		{DockerHub, "DockerHub"},
+		{Google, "GCR"},
+		{GitHub, "GitHub"},
+		{Gitlab, "Gitlab"},
+		{Private, "private"},
+		{Simple, "simple"},
+		{Database, "database"},
+		{RegistryType(255), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			got := test.registryType.String()
+			if got != test.want {
+				t.Errorf("got: %q; want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegistryTypesHaveNames(t *testing.T) {
+	names := map[string]RegistryType{}
+	for _, registryType := range RegistryTypes {
+		name := registryType.String()
+		if name == "" {
+			t.Errorf("registry type (%d) has no name", registryType)
+		}
+		if other, ok := names[name]; ok {
+			t.Errorf("registry types (%d) and (%d) have the same name: %q", registryType, other, name)
+		}
+		names[name] = registryType
+	}
+}
+
+func TestRegistryTypeMarshalText(t *testing.T) {
+	for _, registryType := range append([]RegistryType{Undefined}, RegistryTypes...) {
+		t.Run(registryType.String(), func(t *testing.T) {
+			text, err := registryType.MarshalText()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got RegistryType
+			if err := got.UnmarshalText(text); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != registryType {
+				t.Errorf("got: %v; want: %v", got, registryType)
+			}
+		})
+	}
+
+	if _, err := RegistryType(255).MarshalText(); err == nil {
+		t.Error("expected error marshaling invalid registry type")
+	}
+}
+
+func TestRegistryTypeUnmarshalText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    RegistryType
+		wantErr bool
+	}{
+		{"", Undefined, false},
+		{"DockerHub", DockerHub, false},
+		{"dockerhub", DockerHub, false},
+		{"GCR", Google, false},
+		{"PRIVATE", Private, false},
+		{"database", Database, false},
+		{"quay", Undefined, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			var got RegistryType
+			err := got.UnmarshalText([]byte(test.text))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error: %v; want error: %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got: %v; want: %v", got, test.want)
+			}
+		})
+	}
+}